@@ -0,0 +1,461 @@
+package xeger
+
+import (
+	"fmt"
+	"math/big"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxAnalyzedLen bounds the lengths Analyze reports. Larger maximums, which
+// only arise from deeply nested repeats, are reported as unbounded.
+const maxAnalyzedLen = 1 << 40
+
+// Analysis describes the strings a pattern can match, without generating any.
+type Analysis struct {
+	// MinLen and MaxLen bound the length in runes of any match. MaxLen is -1
+	// when the pattern can match arbitrarily long strings.
+	MinLen int
+	MaxLen int
+
+	// PotentiallyExplosive is set when a repeating quantifier is nested
+	// inside another, as in (a*)*. The counts of such patterns multiply,
+	// so generated strings can grow very large with generous repeat caps.
+	PotentiallyExplosive bool
+}
+
+// Analyze walks the pattern and reports what it can match.
+func (x *Xeger) Analyze() Analysis {
+	min, max := lengthBounds(x.re)
+	return Analysis{
+		MinLen:               min,
+		MaxLen:               max,
+		PotentiallyExplosive: nestedRepeat(x.re, false),
+	}
+}
+
+// Summary describes the pattern in a few lines of plain text, without
+// generating anything: whether it is finite, how many matches it has if so,
+// the bounds on a match's length in runes, whether it is potentially
+// explosive, and the ops of its parsed tree. For [ab]{2}|c it gives
+//
+//	pattern: [ab]{2}|c
+//	finite: true
+//	matches: 5
+//	length: 1 to 2
+//	explosive: false
+//	ops: OpLiteral, OpCharClass, OpRepeat, OpAlternate
+//
+// Ops are listed in the order of the syntax.Op constants. The match count
+// is as CountMatches gives it, and an unbounded length is written as
+// unbounded.
+func (x *Xeger) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pattern: %s\n", x.pattern)
+	fmt.Fprintf(&b, "finite: %t\n", x.IsFinite())
+	if n, ok := x.CountMatches(); ok {
+		fmt.Fprintf(&b, "matches: %v\n", n)
+	}
+	a := x.Analyze()
+	if a.MaxLen == -1 {
+		fmt.Fprintf(&b, "length: %d to unbounded\n", a.MinLen)
+	} else {
+		fmt.Fprintf(&b, "length: %d to %d\n", a.MinLen, a.MaxLen)
+	}
+	fmt.Fprintf(&b, "explosive: %t\n", a.PotentiallyExplosive)
+	var names []string
+	for _, op := range opsOf(x.re, nil) {
+		names = append(names, OpName(op))
+	}
+	fmt.Fprintf(&b, "ops: %s\n", strings.Join(names, ", "))
+	return b.String()
+}
+
+// opsOf adds the ops of re to ops, keeping them sorted and distinct.
+func opsOf(re *syntax.Regexp, ops []syntax.Op) []syntax.Op {
+	if i, found := slices.BinarySearch(ops, re.Op); !found {
+		ops = slices.Insert(ops, i, re.Op)
+	}
+	for _, sub := range re.Sub {
+		ops = opsOf(sub, ops)
+	}
+	return ops
+}
+
+// IsFinite reports whether the pattern matches only finitely many strings,
+// such as [ab]{1,3}, as opposed to patterns like a+. A repeat of something
+// zero-width, as in (?:^)*, only ever matches the empty string and so is
+// finite.
+func (x *Xeger) IsFinite() bool {
+	return finite(x.re)
+}
+
+// finite reports whether re matches finitely many strings.
+func finite(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return zeroWidth(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Max == -1 {
+			return zeroWidth(re.Sub[0])
+		}
+	}
+	for _, sub := range re.Sub {
+		if !finite(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// CountMatches returns how many strings the pattern can generate, or false
+// if the pattern is not finite. It counts the distinct ways of generating a
+// string, so a string producible in two ways, as both branches of a|a or
+// from (a|aa){2} as either a+aa or aa+a, is counted twice; for patterns
+// without such ambiguity the count is exact. Zero-width parts count as a
+// single way of matching, and word boundaries are not checked, so a\bb
+// counts one match even though no string satisfies it on its own.
+func (x *Xeger) CountMatches() (*big.Int, bool) {
+	if !finite(x.re) {
+		return nil, false
+	}
+	return countMatches(x.re), true
+}
+
+// countMatches returns the number of ways of generating a string from the
+// finite pattern re.
+func countMatches(re *syntax.Regexp) *big.Int {
+	if zeroWidth(re) {
+		return big.NewInt(1)
+	}
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return new(big.Int)
+	case syntax.OpLiteral:
+		n := big.NewInt(1)
+		if re.Flags&syntax.FoldCase != 0 {
+			for _, r := range re.Rune {
+				n.Mul(n, big.NewInt(int64(orbitSize(r))))
+			}
+		}
+		return n
+	case syntax.OpCharClass:
+		return big.NewInt(classSize(re.Rune))
+	case syntax.OpAnyChar:
+		return big.NewInt(classSize(nonSurrogates))
+	case syntax.OpAnyCharNotNL:
+		return big.NewInt(classSize(nonSurrogates) - 1)
+	case syntax.OpCapture:
+		return countMatches(re.Sub[0])
+	case syntax.OpQuest:
+		return new(big.Int).Add(big.NewInt(1), countMatches(re.Sub[0]))
+	case syntax.OpRepeat:
+		sub := countMatches(re.Sub[0])
+		n, pow := new(big.Int), new(big.Int).Exp(sub, big.NewInt(int64(re.Min)), nil)
+		for i := re.Min; i <= re.Max; i++ {
+			n.Add(n, pow)
+			pow.Mul(pow, sub)
+		}
+		return n
+	case syntax.OpConcat:
+		n := big.NewInt(1)
+		for _, sub := range re.Sub {
+			n.Mul(n, countMatches(sub))
+		}
+		return n
+	case syntax.OpAlternate:
+		n := new(big.Int)
+		for _, sub := range re.Sub {
+			n.Add(n, countMatches(sub))
+		}
+		return n
+	}
+	return big.NewInt(1)
+}
+
+// FirstRunes returns, in increasing order, every rune that can begin a
+// match of the pattern, like the FIRST set of a grammar: [ab]x|cy gives
+// a, b and c, and x?y gives x and y. A pattern that can match the empty
+// string says nothing about that match here. The set is taken from the
+// pattern alone, so it is larger than what Generate emits when options
+// such as WithRuneWeights or WithWhitespaceRune narrow the choices, and
+// for a pattern starting with . it holds over a million runes.
+func (x *Xeger) FirstRunes() []rune {
+	ranges, _ := firstRanges(x.re)
+	var runes []rune
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+// firstRanges returns the runes that can begin a match of re, as sorted
+// disjoint lo, hi pairs, and whether re can match the empty string.
+func firstRanges(re *syntax.Regexp) (ranges []rune, nullable bool) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return nil, false
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return nil, true
+		}
+		r := re.Rune[0]
+		ranges = []rune{r, r}
+		if re.Flags&syntax.FoldCase != 0 {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				ranges = unionRanges(ranges, []rune{f, f})
+			}
+		}
+		return ranges, false
+	case syntax.OpCharClass:
+		return re.Rune, false
+	case syntax.OpAnyChar:
+		return nonSurrogates, false
+	case syntax.OpAnyCharNotNL:
+		return intersectRanges(nonSurrogates, negateRanges([]rune{'\n', '\n'})), false
+	case syntax.OpCapture, syntax.OpPlus:
+		return firstRanges(re.Sub[0])
+	case syntax.OpStar, syntax.OpQuest:
+		ranges, _ = firstRanges(re.Sub[0])
+		return ranges, true
+	case syntax.OpRepeat:
+		if re.Max == 0 {
+			return nil, true
+		}
+		ranges, nullable = firstRanges(re.Sub[0])
+		return ranges, nullable || re.Min == 0
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			first, ok := firstRanges(sub)
+			ranges = unionRanges(ranges, first)
+			if !ok {
+				return ranges, false
+			}
+		}
+		return ranges, true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			first, ok := firstRanges(sub)
+			ranges = unionRanges(ranges, first)
+			nullable = nullable || ok
+		}
+		return ranges, nullable
+	}
+	// empty matches and zero-width assertions
+	return nil, true
+}
+
+// emittable returns the runes generation can emit for re, as sorted
+// disjoint lo, hi pairs. Unlike the runes re matches, . contributes only
+// the printable ASCII characters it draws from, and newline if it matches
+// one.
+func emittable(re *syntax.Regexp) []rune {
+	switch re.Op {
+	case syntax.OpLiteral:
+		var ranges []rune
+		for _, r := range re.Rune {
+			ranges = unionRanges(ranges, []rune{r, r})
+			if re.Flags&syntax.FoldCase != 0 {
+				for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+					ranges = unionRanges(ranges, []rune{f, f})
+				}
+			}
+		}
+		return ranges
+	case syntax.OpCharClass:
+		return re.Rune
+	case syntax.OpAnyChar:
+		return unionRanges(printableASCII, []rune{'\n', '\n'})
+	case syntax.OpAnyCharNotNL:
+		return printableASCII
+	case syntax.OpRepeat:
+		if re.Max == 0 {
+			return nil
+		}
+	}
+	var ranges []rune
+	for _, sub := range re.Sub {
+		ranges = unionRanges(ranges, emittable(sub))
+	}
+	return ranges
+}
+
+// zeroWidth reports whether re matches only the empty string, being made
+// up of empty matches and zero-width assertions alone.
+func zeroWidth(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	case syntax.OpRepeat:
+		if re.Max == 0 {
+			return true
+		}
+	case syntax.OpCapture, syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpConcat:
+	case syntax.OpAlternate:
+		if len(re.Sub) == 0 {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, sub := range re.Sub {
+		if !zeroWidth(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRepeating reports whether re is a quantifier that can repeat its
+// subexpression more than once.
+func isRepeating(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		return re.Max == -1 || re.Max > 1
+	}
+	return false
+}
+
+// nestedRepeat reports whether re contains a repeating quantifier, or one
+// nested inside another when inside is false.
+func nestedRepeat(re *syntax.Regexp, inside bool) bool {
+	if isRepeating(re) {
+		if inside {
+			return true
+		}
+		inside = true
+	}
+	for _, sub := range re.Sub {
+		if nestedRepeat(sub, inside) {
+			return true
+		}
+	}
+	return false
+}
+
+// lengthBounds returns the minimum and maximum rune length of strings
+// matched by re, with a max of -1 meaning unbounded.
+func lengthBounds(re *syntax.Regexp) (min, max int) {
+	return measure(re, false)
+}
+
+// byteBounds is like lengthBounds but measures UTF-8 bytes.
+func byteBounds(re *syntax.Regexp) (min, max int) {
+	return measure(re, true)
+}
+
+// measure returns the length bounds of strings matched by re, in bytes if
+// inBytes is set and otherwise in runes.
+func measure(re *syntax.Regexp, inBytes bool) (min, max int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if !inBytes {
+			return len(re.Rune), len(re.Rune)
+		}
+		for _, r := range re.Rune {
+			lo, hi := utf8.RuneLen(r), utf8.RuneLen(r)
+			if re.Flags&syntax.FoldCase != 0 {
+				for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+					if n := utf8.RuneLen(f); n < lo {
+						lo = n
+					} else if n > hi {
+						hi = n
+					}
+				}
+			}
+			min, max = min+lo, max+hi
+		}
+		return min, max
+	case syntax.OpCharClass:
+		if !inBytes || len(re.Rune) == 0 {
+			return 1, 1
+		}
+		return utf8.RuneLen(re.Rune[0]), utf8.RuneLen(re.Rune[len(re.Rune)-1])
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		// . matches any rune, but is only generated as printable ASCII
+		// or a newline
+		return 1, 1
+	case syntax.OpCapture:
+		return measure(re.Sub[0], inBytes)
+	case syntax.OpStar:
+		return repeatBounds(re.Sub[0], 0, -1, inBytes)
+	case syntax.OpPlus:
+		return repeatBounds(re.Sub[0], 1, -1, inBytes)
+	case syntax.OpQuest:
+		return repeatBounds(re.Sub[0], 0, 1, inBytes)
+	case syntax.OpRepeat:
+		return repeatBounds(re.Sub[0], re.Min, re.Max, inBytes)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			subMin, subMax := measure(sub, inBytes)
+			min = saturate(addLen(min, subMin))
+			max = addLen(max, subMax)
+		}
+		return min, max
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			subMin, subMax := measure(sub, inBytes)
+			if i == 0 || subMin < min {
+				min = subMin
+			}
+			if max != -1 && (subMax == -1 || subMax > max) {
+				max = subMax
+			}
+		}
+		return min, max
+	}
+	// empty matches and zero-width assertions
+	return 0, 0
+}
+
+// repeatBounds returns the length bounds of sub repeated between lo and hi
+// times, with hi of -1 meaning unbounded.
+func repeatBounds(sub *syntax.Regexp, lo, hi int, inBytes bool) (min, max int) {
+	subMin, subMax := measure(sub, inBytes)
+	min = saturate(mulLen(subMin, lo))
+	switch {
+	case subMax == 0:
+		max = 0
+	case hi == -1 || subMax == -1:
+		max = -1
+	default:
+		max = mulLen(subMax, hi)
+	}
+	return min, max
+}
+
+// addLen adds two lengths, where -1 is unbounded and results past
+// maxAnalyzedLen saturate to unbounded.
+func addLen(a, b int) int {
+	if a == -1 || b == -1 || a+b > maxAnalyzedLen {
+		return -1
+	}
+	return a + b
+}
+
+// mulLen multiplies two lengths with the same conventions as addLen.
+func mulLen(a, b int) int {
+	if a == -1 || b == -1 {
+		return -1
+	}
+	if a != 0 && b > maxAnalyzedLen/a {
+		return -1
+	}
+	return a * b
+}
+
+// saturate maps an overflowed minimum length to maxAnalyzedLen.
+func saturate(n int) int {
+	if n == -1 {
+		return maxAnalyzedLen
+	}
+	return n
+}
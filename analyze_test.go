@@ -0,0 +1,239 @@
+package xeger
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestAnalyzeLengths(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		MinLen  int
+		MaxLen  int
+	}{
+		{``, 0, 0},
+		{`^$`, 0, 0},
+		{`abc`, 3, 3},
+		{`[a-z]`, 1, 1},
+		{`a?`, 0, 1},
+		{`a*`, 0, -1},
+		{`(ab)+`, 2, -1},
+		{`[0-9]{3,5}`, 3, 5},
+		{`x{2,}`, 2, -1},
+		{`a|bcd`, 1, 3},
+		{`(a|b*)c`, 1, -1},
+		{`(?:)*`, 0, 0},
+		{`^[0-9a-z]+\[[0-9]{3,5}\]$`, 6, -1},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		a := iRe.Analyze()
+		if a.MinLen != test.MinLen || a.MaxLen != test.MaxLen {
+			t.Errorf("%s: got lengths [%d, %d], want [%d, %d]", test.Pattern, a.MinLen, a.MaxLen, test.MinLen, test.MaxLen)
+		}
+	}
+}
+
+func TestAnalyzePotentiallyExplosive(t *testing.T) {
+	var tests = []struct {
+		Pattern   string
+		Explosive bool
+	}{
+		{`a*`, false},
+		{`a*b+c{2,5}`, false},
+		{`(a?)*`, false},
+		{`(a{1}){3}`, false},
+		{`(a*)*`, true},
+		{`(a+b)+`, true},
+		{`((ab){2,}c)*`, true},
+		{`(x|(y*))+`, true},
+		{`(a{2,3}){2,3}`, true},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if got := iRe.Analyze().PotentiallyExplosive; got != test.Explosive {
+			t.Errorf("%s: got PotentiallyExplosive %v, want %v", test.Pattern, got, test.Explosive)
+		}
+	}
+}
+
+func TestZeroWidthPatterns(t *testing.T) {
+	for _, pattern := range []string{
+		``, `(?:)`, `^$`, `\A\z`, `\B`, `(?m)^$`, `(?:^|$)`, `(^)*`, `(?:\b)+$`, `a{0}`, `^(?:|$){3}`,
+	} {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		if !iRe.zeroWidth {
+			t.Errorf("%s: not recognized as zero-width", pattern)
+		}
+		if !iRe.IsFinite() {
+			t.Errorf("%s: not finite", pattern)
+		}
+		if n, ok := iRe.CountMatches(); !ok || n.Int64() != 1 {
+			t.Errorf("%s: got count %v, %v, want a single match", pattern, n, ok)
+		}
+		for i := 0; i < 5; i++ {
+			s, err := iRe.Generate()
+			if err != nil || s != "" {
+				t.Fatalf("%s: got %q, %v, want the empty string", pattern, s, err)
+			}
+		}
+	}
+
+	// \b needs a word character beside it, so alone it has no whole
+	// matches, but it still generates nothing.
+	iRe, err := NewInverseRegex(`\b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := iRe.Generate(); err != nil || s != "" {
+		t.Errorf(`\b: got %q, %v, want the empty string`, s, err)
+	}
+
+	for _, pattern := range []string{`a`, `^a?$`, `[^\x00-\x{10FFFF}]`, `(?:)|x`} {
+		iRe, err := NewInverseRegex(pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		if iRe.zeroWidth {
+			t.Errorf("%s: wrongly recognized as zero-width", pattern)
+		}
+	}
+}
+
+func TestWordBoundaryZeroWidth(t *testing.T) {
+	iRe, err := NewInverseRegex(`\bfoo\b-\B-`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := iRe.GenerateValid()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "foo--" {
+		t.Errorf("got %q, want %q", s, "foo--")
+	}
+}
+
+func TestCountMatches(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Count   string
+	}{
+		{`abc`, "1"},
+		{`[ab][01]`, "4"},
+		{`a?`, "2"},
+		{`[0-9]{2,3}`, "1100"},
+		{`(?i)ab`, "4"},
+		{`(?i)k`, "3"},
+		{`cat|dog|[x-z]`, "5"},
+		{`(?:[ab]|c){0,2}`, "13"},
+		{`[a-z]{20}`, "19928148895209409152340197376"},
+		{`[^\x00-\x{10FFFF}]`, "0"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		n, ok := iRe.CountMatches()
+		if !ok || !iRe.IsFinite() {
+			t.Fatalf("%s: not finite", test.Pattern)
+		}
+		if n.String() != test.Count {
+			t.Errorf("%s: got count %s, want %s", test.Pattern, n, test.Count)
+		}
+	}
+
+	for _, pattern := range []string{`a*`, `x+y`, `[0-9]{2,}`, `(a|b*)c`} {
+		iRe, err := NewInverseRegex(pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		if iRe.IsFinite() {
+			t.Errorf("%s: reported finite", pattern)
+		}
+		if n, ok := iRe.CountMatches(); ok {
+			t.Errorf("%s: got count %s for an infinite pattern", pattern, n)
+		}
+	}
+}
+
+func TestFirstRunes(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`abc`, "a"},
+		{`[ab]x|cy`, "abc"},
+		{`x?y`, "xy"},
+		{`(?:a*b*)?c`, "abc"},
+		{`^(?:foo|)\bbar`, "bf"},
+		{`(?i)k`, "Kk\u212a"},
+		{`[0-9]{0,2}-`, "-0123456789"},
+		{`(?:z{0}|q)+r`, "qr"},
+		{`a*`, "a"},
+		{`^$`, ""},
+		{`[^\x00-\x{10FFFF}]x`, ""},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if got := string(iRe.FirstRunes()); got != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+
+	iRe, err := NewInverseRegex(`.+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rune(len(iRe.FirstRunes())), unicode.MaxRune+1-0x800-1; got != want {
+		t.Errorf(".+: got %d first runes, want %d", got, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`[ab]{2}|c`, `pattern: [ab]{2}|c
+finite: true
+matches: 5
+length: 1 to 2
+explosive: false
+ops: OpLiteral, OpCharClass, OpRepeat, OpAlternate
+`},
+		{`^(?:x+)*$`, `pattern: ^(?:x+)*$
+finite: false
+length: 0 to unbounded
+explosive: true
+ops: OpLiteral, OpBeginText, OpEndText, OpStar, OpPlus, OpConcat
+`},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if got := iRe.Summary(); got != test.Want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.Pattern, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package xeger
+
+import "fmt"
+
+// defaultNewlineProbability is how often a . that matches newlines emits
+// one unless configured otherwise.
+const defaultNewlineProbability = 0.05
+
+// printableASCII is the range . draws its other runes from.
+var printableASCII = []rune{' ', '~'}
+
+// WithNewlineProbability sets the probability p that a . able to match a
+// newline, as in (?s:.), emits "\n". Otherwise, and always for a plain .,
+// it emits a printable ASCII character. The default is 0.05, which keeps
+// output mostly on one line.
+func WithNewlineProbability(p float64) Option {
+	return func(x *Xeger) error {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("xeger: newline probability must be in [0, 1], got %v", p)
+		}
+		x.newlineProb = p
+		return nil
+	}
+}
+
+// anyRune returns a random rune for ., which may be a newline if nl is set.
+func (g *generator) anyRune(nl bool) rune {
+	if p := g.x.newlineProb; nl && p > 0 && !g.x.printable && g.rng.Float64() < p {
+		return '\n'
+	}
+	return g.pickRune(printableASCII, nil)
+}
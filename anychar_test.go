@@ -0,0 +1,86 @@
+package xeger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnyChar(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+		Min     int
+		Max     int
+	}{
+		{`.{1000}`, []Option{WithNewlineProbability(1)}, 0, 0},
+		{`(?s:.{1000})`, []Option{WithNewlineProbability(0)}, 0, 0},
+		{`(?s:.{1000})`, []Option{WithNewlineProbability(1)}, 1000, 1000},
+		{`(?s:.{1000})`, []Option{WithNewlineProbability(0.2)}, 140, 260},
+		{`(?s:.{1000})`, nil, 10, 100},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatalf("%s: %v", test.Pattern, err)
+		}
+		if len(s) != 1000 {
+			t.Fatalf("%s: got %d bytes, want 1000", test.Pattern, len(s))
+		}
+		for _, r := range s {
+			if r != '\n' && (r < ' ' || r > '~') {
+				t.Fatalf("%s: got unprintable %q", test.Pattern, r)
+			}
+		}
+		if n := strings.Count(s, "\n"); n < test.Min || n > test.Max {
+			t.Errorf("%s: got %d newlines, want between %d and %d", test.Pattern, n, test.Min, test.Max)
+		}
+	}
+
+	if _, err := NewInverseRegex(`.`, WithNewlineProbability(-0.1)); err == nil {
+		t.Errorf("expected an error for an out of range probability")
+	}
+}
+
+func TestRepeatedAnyChar(t *testing.T) {
+	for _, test := range []struct {
+		Pattern string
+		Min     int
+	}{
+		{`.*`, 0},
+		{`.+`, 1},
+		{`(?s).*`, 0},
+		{`x.+y`, 3},
+	} {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		lengths := make(map[int]bool)
+		fresh := false
+		for i := 0; i < 100; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if len(s) < test.Min {
+				t.Fatalf("%s: %q is shorter than %d", test.Pattern, s, test.Min)
+			}
+			lengths[len(s)] = true
+			// each copy draws its own rune rather than repeating the first
+			if body := strings.Trim(s, "xy"); len(body) > 1 && strings.Count(body, body[:1]) != len(body) {
+				fresh = true
+			}
+		}
+		if len(lengths) < 5 {
+			t.Errorf("%s: only %d distinct lengths in 100 strings", test.Pattern, len(lengths))
+		}
+		if !fresh {
+			t.Errorf("%s: every string repeated a single rune", test.Pattern)
+		}
+	}
+}
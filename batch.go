@@ -0,0 +1,126 @@
+package xeger
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"regexp/syntax"
+)
+
+// WithShuffle makes GenerateN return its strings in a shuffled order, so
+// that sequential-looking output, such as the increasing ids a counting
+// pattern tends to give, is scattered. The permutation depends only on the
+// seed and n, so the result is as reproducible as without shuffling.
+func WithShuffle(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.shuffle = enabled
+		return nil
+	}
+}
+
+// GenerateN returns n generated strings drawn in sequence from the
+// instance's random source, shuffled if WithShuffle is set.
+func (x *Xeger) GenerateN(n int) ([]string, error) {
+	out := make([]string, 0, n)
+	err := x.GenerateEach(n, func(s string) error {
+		out = append(out, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if x.shuffle {
+		rand.New(rand.NewSource(x.seed)).Shuffle(n, func(i, j int) {
+			out[i], out[j] = out[j], out[i]
+		})
+	}
+	return out, nil
+}
+
+// GenerateDiverse generates n strings that between them use as many
+// distinct runes as possible: each char class prefers runes it has not yet
+// produced anywhere in the batch, and only repeats runes once it has used
+// them all. Elements whose generation fails are left empty.
+func (x *Xeger) GenerateDiverse(n int) []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.diverse = make(map[*syntax.Regexp]*dealer)
+	out := make([]string, n)
+	for i := range out {
+		out[i], _ = g.generate()
+	}
+	return out
+}
+
+// GenerateEach generates n strings, passing each to fn as it is produced
+// rather than collecting them. It stops at the first error from generation
+// or from fn and returns it.
+func (x *Xeger) GenerateEach(n int, fn func(string) error) error {
+	for i := 0; i < n; i++ {
+		s, err := x.Generate()
+		if err != nil {
+			return err
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateLines writes n generated strings to w, each followed by a
+// newline. Lines are written as they are produced, reusing one buffer, so
+// memory use does not grow with n. It stops at the first error from
+// generation or from w and returns it.
+func (x *Xeger) GenerateLines(w io.Writer, n int) error {
+	var buf []byte
+	for i := 0; i < n; i++ {
+		var err error
+		buf, err = x.AppendTo(buf[:0])
+		if err != nil {
+			return err
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateFilled generates matches joined by sep until the result is at
+// least targetBytes long, such as building a synthetic log file of a given
+// size from a pattern for one line. It stops at the first match to reach
+// the target, so the result overshoots by less than one separator and
+// match. A pattern that only matches the empty string cannot fill anything
+// without a separator, which is reported as ErrLengthInfeasible. Without a
+// separator, a pattern that can match non-empty strings but keeps
+// generating empty ones fails with ErrRetryExhausted once the retry limit
+// of empty matches in a row is reached.
+func (x *Xeger) GenerateFilled(sep string, targetBytes int) (string, error) {
+	if x.zeroWidth && sep == "" && targetBytes > 0 {
+		return "", fmt.Errorf("%w: empty matches of %s cannot fill %d bytes", ErrLengthInfeasible, x.re, targetBytes)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	var buf []byte
+	stalled := 0
+	for n := 0; len(buf) < targetBytes; n++ {
+		before := len(buf)
+		if n > 0 {
+			buf = append(buf, sep...)
+		}
+		var err error
+		if buf, err = g.appendGenerated(buf); err != nil {
+			return "", err
+		}
+		if len(buf) > before {
+			stalled = 0
+		} else if stalled++; stalled == x.maxRetries {
+			return "", fmt.Errorf("%w: %d empty matches of %s in a row at %d of %d bytes", ErrRetryExhausted, stalled, x.re, len(buf), targetBytes)
+		}
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,229 @@
+package xeger
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGenerateEach(t *testing.T) {
+	a, err := NewInverseRegex(`[a-z]{6}`, WithSeed(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewInverseRegex(`[a-z]{6}`, WithSeed(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := a.GenerateN(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 20 {
+		t.Fatalf("GenerateN returned %d strings, want 20", len(want))
+	}
+	var got []string
+	err = b.GenerateEach(20, func(s string) error {
+		got = append(got, s)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGenerateEachStopsEarly(t *testing.T) {
+	iRe, err := NewInverseRegex(`x`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = iRe.GenerateEach(10, func(string) error {
+		calls++
+		if calls == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("got error %v, want the callback's error", err)
+	}
+	if calls != 3 {
+		t.Errorf("callback ran %d times, want 3", calls)
+	}
+}
+
+func TestWithShuffle(t *testing.T) {
+	plain, err := NewInverseRegex(`[0-9]{3}`, WithSeed(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := plain.GenerateN(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shuffled := func() []string {
+		iRe, err := NewInverseRegex(`[0-9]{3}`, WithSeed(4), WithShuffle(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := iRe.GenerateN(20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	got := shuffled()
+	if !reflect.DeepEqual(got, shuffled()) {
+		t.Errorf("shuffled output differs between runs")
+	}
+	if reflect.DeepEqual(got, want) {
+		t.Errorf("output was not shuffled: %v", got)
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shuffling changed the strings: got %v, want %v", got, want)
+	}
+}
+
+func TestGenerateDiverse(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]-[0-9]`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	letters := make(map[byte]bool)
+	digits := make(map[byte]bool)
+	for _, s := range iRe.GenerateDiverse(26) {
+		if !iRe.regexp.MatchString(s) {
+			t.Fatalf("%q does not match", s)
+		}
+		letters[s[0]] = true
+		digits[s[2]] = true
+	}
+	if len(letters) != 26 {
+		t.Errorf("26 samples used %d distinct letters, want all 26", len(letters))
+	}
+	if len(digits) != 10 {
+		t.Errorf("26 samples used %d distinct digits, want all 10", len(digits))
+	}
+}
+
+func TestGenerateLines(t *testing.T) {
+	a, err := NewInverseRegex(`[a-z]{2,6}`, WithSeed(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewInverseRegex(`[a-z]{2,6}`, WithSeed(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := a.GenerateLines(&sb, 50); err != nil {
+		t.Fatal(err)
+	}
+	want, err := b.GenerateN(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("got %q, want the lines of %q", got, want)
+	}
+}
+
+type failingWriter struct{ writes, limit int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == w.limit {
+		return 0, errors.New("disk full")
+	}
+	w.writes++
+	return len(p), nil
+}
+
+func TestGenerateLinesWriteError(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{6}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &failingWriter{limit: 3}
+	if err := iRe.GenerateLines(w, 10); err == nil || err.Error() != "disk full" {
+		t.Errorf("got error %v, want the writer's", err)
+	}
+	if w.writes != 3 {
+		t.Errorf("got %d writes, want 3", w.writes)
+	}
+}
+
+func TestGenerateFilled(t *testing.T) {
+	line := regexp.MustCompile(`^[a-z]{3,8} [0-9]{1,4}$`)
+	filled := func(seed int64) string {
+		iRe, err := NewInverseRegex(`[a-z]{3,8} [0-9]{1,4}`, WithSeed(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := iRe.GenerateFilled("\n", 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s := filled(1)
+	if s != filled(1) {
+		t.Errorf("same seed filled differently")
+	}
+	if s == filled(2) {
+		t.Errorf("different seeds filled alike")
+	}
+	lines := strings.Split(s, "\n")
+	last := len(s) - len(lines[len(lines)-1]) - 1
+	if len(s) < 1000 || last >= 1000 {
+		t.Errorf("filled %d bytes, with the last line starting at %d", len(s), last+1)
+	}
+	for _, l := range lines {
+		if !line.MatchString(l) {
+			t.Fatalf("line %q does not match", l)
+		}
+	}
+
+	iRe, err := NewInverseRegex(`^`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.GenerateFilled("", 10); !errors.Is(err, ErrLengthInfeasible) {
+		t.Errorf("got %v, want ErrLengthInfeasible", err)
+	}
+	if s, err := iRe.GenerateFilled(",", 3); s != ",,," || err != nil {
+		t.Errorf("got %q, %v", s, err)
+	}
+}
+
+func TestGenerateFilledEmptyMatches(t *testing.T) {
+	for _, test := range []struct {
+		Pattern string
+		Opts    []Option
+	}{
+		{`(?P<n>a*)`, []Option{WithCaptureTemplate("n", "")}},
+		{`(?:[^\x00-\x{10FFFF}])?`, []Option{WithQuestProbability(0)}},
+	} {
+		iRe, err := NewInverseRegex(test.Pattern, test.Opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if _, err := iRe.GenerateFilled("", 10); !errors.Is(err, ErrRetryExhausted) {
+			t.Errorf("%s: got %v, want ErrRetryExhausted", test.Pattern, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package xeger
+
+// GenerateBytes is like Generate but returns the result as a byte slice.
+func (x *Xeger) GenerateBytes() ([]byte, error) {
+	return x.AppendTo(nil)
+}
+
+// AppendTo appends a generated string to dst and returns the extended
+// slice, following the strconv.AppendX convention. Reusing dst across calls
+// avoids allocating a new buffer for every generation. On error dst is
+// returned unchanged.
+func (x *Xeger) AppendTo(dst []byte) ([]byte, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.newGenerator(x.rng).appendGenerated(dst)
+}
@@ -0,0 +1,47 @@
+package xeger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAppendTo(t *testing.T) {
+	a, err := NewInverseRegex(`[a-z]{4}-[0-9]{4}`, WithSeed(11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewInverseRegex(`[a-z]{4}-[0-9]{4}`, WithSeed(11))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := []byte("prefix:")
+	for i := 0; i < 10; i++ {
+		want, err := b.GenerateBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err = a.AppendTo(buf[:len("prefix:")])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(buf, []byte("prefix:")) {
+			t.Fatalf("AppendTo clobbered dst: %q", buf)
+		}
+		if got := buf[len("prefix:"):]; !bytes.Equal(got, want) {
+			t.Errorf("call %d: AppendTo gave %q, GenerateBytes gave %q", i, got, want)
+		}
+	}
+}
+
+func BenchmarkAppendTo(b *testing.B) {
+	iRe, err := NewInverseRegex(`[a-z]{4}-[0-9]{4}`, WithSeed(1))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf []byte
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ = iRe.AppendTo(buf[:0])
+	}
+}
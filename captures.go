@@ -0,0 +1,258 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// WithCaptureTemplate fixes the content of the capture group called name to
+// value, while the rest of the pattern is generated randomly. This is a way
+// to fill a group consistently, such as pinning a version field, given that
+// RE2 has no backreferences. It is an error if the pattern has no group
+// called name or value is not matched by the group's subpattern.
+func WithCaptureTemplate(name string, value string) Option {
+	return func(x *Xeger) error {
+		if err := checkCaptureValue(x.re, name, value); err != nil {
+			return err
+		}
+		if x.captureValues == nil {
+			x.captureValues = make(map[string]string)
+		}
+		x.captureValues[name] = value
+		return nil
+	}
+}
+
+// checkCaptureValue returns an error unless re has at least one capture
+// called name and value matches the subpattern of every such capture.
+func checkCaptureValue(re *syntax.Regexp, name, value string) error {
+	var caps []*syntax.Regexp
+	if name != "" {
+		caps = namedCaptures(re, name, nil)
+	}
+	if len(caps) == 0 {
+		return fmt.Errorf("xeger: no capture group named %q", name)
+	}
+	for _, c := range caps {
+		sub, err := regexp.Compile(`^(?:` + c.Sub[0].String() + `)$`)
+		if err != nil {
+			return err
+		}
+		if !sub.MatchString(value) {
+			return fmt.Errorf("xeger: value %q does not match capture %q: %s", value, name, c.Sub[0])
+		}
+	}
+	return nil
+}
+
+// namedCaptures appends to caps every capture in re called name.
+func namedCaptures(re *syntax.Regexp, name string, caps []*syntax.Regexp) []*syntax.Regexp {
+	if re.Op == syntax.OpCapture && re.Name == name {
+		caps = append(caps, re)
+	}
+	for _, sub := range re.Sub {
+		caps = namedCaptures(sub, name, caps)
+	}
+	return caps
+}
+
+// CaptureNames returns the names of the named capture groups in the
+// pattern, in the order their opening parentheses appear. It is the inverse
+// side of regexp.Regexp.SubexpNames, without entries for unnamed groups.
+func (x *Xeger) CaptureNames() []string {
+	return captureNames(x.re, nil)
+}
+
+// captureNames appends the names of the named captures in re to names.
+func captureNames(re *syntax.Regexp, names []string) []string {
+	if re.Op == syntax.OpCapture && re.Name != "" {
+		names = append(names, re.Name)
+	}
+	for _, sub := range re.Sub {
+		names = captureNames(sub, names)
+	}
+	return names
+}
+
+// WithCaptureLength constrains the content generated for the capture group
+// called name to between min and max runes, such as making the [0-9]+ in
+// (?P<pin>[0-9]+) exactly four digits. The group's content is re-rolled
+// until it fits, with its unbounded quantifiers choosing counts uniformly
+// up to at least max. It is an error if there is no such group or its
+// subpattern cannot produce a string of that length.
+func WithCaptureLength(name string, min, max int) Option {
+	return func(x *Xeger) error {
+		if min < 0 || max < min {
+			return fmt.Errorf("xeger: invalid length range [%d, %d]", min, max)
+		}
+		var caps []*syntax.Regexp
+		if name != "" {
+			caps = namedCaptures(x.re, name, nil)
+		}
+		if len(caps) == 0 {
+			return fmt.Errorf("xeger: no capture group named %q", name)
+		}
+		for _, c := range caps {
+			lo, hi := lengthBounds(c.Sub[0])
+			if max < lo || (hi != -1 && min > hi) {
+				return fmt.Errorf("%w: range [%d, %d] against capture %q lengths [%d, %d]", ErrLengthInfeasible, min, max, name, lo, hi)
+			}
+		}
+		if x.captureLengths == nil {
+			x.captureLengths = make(map[string][2]int)
+		}
+		x.captureLengths[name] = [2]int{min, max}
+		return nil
+	}
+}
+
+// captureWithLength generates the content of the capture re until its rune
+// length falls within bounds.
+func (g *generator) captureWithLength(re *syntax.Regexp, bounds [2]int) error {
+	savedReps, savedStrategy := g.maxReps, g.repStrategy
+	defer func() { g.maxReps, g.repStrategy = savedReps, savedStrategy }()
+	if g.maxReps < bounds[1] {
+		g.maxReps = bounds[1]
+	}
+	if g.repStrategy == nil {
+		g.repStrategy = UniformReps
+	}
+
+	start := len(g.buf)
+	mark := g.mark()
+	saved := make(map[string]string, len(g.captures))
+	for name, v := range g.captures {
+		saved[name] = v
+	}
+	for i := 0; i < g.x.maxRetries; i++ {
+		g.buf = g.buf[:start]
+		g.rewind(mark)
+		g.restoreCaptures(saved)
+		g.dropSpans(start)
+		g.dropClassPicks(start)
+		if err := g.makeMatch(re.Sub[0]); err != nil {
+			return err
+		}
+		if n := utf8.RuneCount(g.buf[start:]); n >= bounds[0] && n <= bounds[1] {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: capture %q never fit lengths [%d, %d]", ErrRetryExhausted, re.Name, bounds[0], bounds[1])
+}
+
+// restoreCaptures resets the recorded captures to saved, so that groups
+// nested in a rejected attempt at a capture leave no content behind.
+func (g *generator) restoreCaptures(saved map[string]string) {
+	if g.captures == nil {
+		return
+	}
+	for name := range g.captures {
+		delete(g.captures, name)
+	}
+	for name, v := range saved {
+		g.captures[name] = v
+	}
+}
+
+// WithCaptureMarkers writes open before and close after the content of
+// every capture group, so that (ab)(cd) with markers "<" and ">" gives
+// <ab><cd>. This is purely for seeing which part of the output came from
+// which group. Unlike escaping and encoding, the markers are part of the
+// string the configured checks see.
+func WithCaptureMarkers(open, close string) Option {
+	return func(x *Xeger) error {
+		x.markers = []string{open, close}
+		return nil
+	}
+}
+
+// GenerateWithTemplate is like Generate but emits values[name] verbatim
+// wherever the capture group called name occurs, generating the rest of
+// the pattern as usual. It is WithCaptureTemplate for a single call: values
+// take precedence over templates configured on x, and x is left unchanged.
+// It is an error if the pattern has no group for some name or a value is
+// not matched by its group's subpattern.
+func (x *Xeger) GenerateWithTemplate(values map[string]string) (string, error) {
+	merged := make(map[string]string, len(x.captureValues)+len(values))
+	for name, v := range x.captureValues {
+		merged[name] = v
+	}
+	for name, v := range values {
+		if err := checkCaptureValue(x.re, name, v); err != nil {
+			return "", err
+		}
+		merged[name] = v
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.captureValues = merged
+	return g.generate()
+}
+
+// GenerateInto is like GenerateWithTemplate, but the segments are not
+// checked against their groups' subpatterns: segments[name] is inserted
+// verbatim wherever the group called name occurs, whatever it contains, so
+// (?P<id>[0-9]+)-[a-z]{2} with an id of "ID_7" can give ID_7-qe. The result
+// therefore need not match the pattern. Segments take precedence over
+// templates configured on x, and configured checks still apply to the whole
+// string. It is an error if the pattern has no group for some name.
+func (x *Xeger) GenerateInto(segments map[string]string) (string, error) {
+	merged := make(map[string]string, len(x.captureValues)+len(segments))
+	for name, v := range x.captureValues {
+		merged[name] = v
+	}
+	for name, v := range segments {
+		if name == "" || len(namedCaptures(x.re, name, nil)) == 0 {
+			return "", fmt.Errorf("xeger: no capture group named %q", name)
+		}
+		merged[name] = v
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.captureValues = merged
+	return g.generate()
+}
+
+// GenerateTemplate generates a string with placeholder(name) written in
+// place of the content of each named capture group, while everything else,
+// including unnamed groups, is generated as usual. For
+// GET /users/(?P<id>[0-9]+)/posts/(?P<postId>[0-9]+) and a placeholder
+// of "{" + name + "}" it gives GET /users/{id}/posts/{postId}, ready for a
+// templating system. A template is not itself a match, so it is generated
+// in a single attempt without the configured checks or URL encoding. On
+// failure it returns an empty string.
+func (x *Xeger) GenerateTemplate(placeholder func(name string) string) string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.placeholder = placeholder
+	b, err := g.appendOnce(nil)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// GenerateWithCaptures is like Generate but also returns the content
+// generated for each capture group, keyed by name for named groups and by
+// group number for unnamed ones, as FindStringSubmatch numbers them, so
+// (\d+)-(?P<label>\w+) gives keys "1" and "label". When a group is
+// generated more than once, as in ((?P<x>[0-9])){2}, the map holds its last
+// occurrence, matching what regexp reports for the submatch. Groups that
+// took no part in the match, such as one inside a skipped optional, are
+// absent from the map.
+func (x *Xeger) GenerateWithCaptures() (string, map[string]string, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.captures = make(map[string]string)
+	s, err := g.generate()
+	if err != nil {
+		return "", nil, err
+	}
+	return s, g.captures, nil
+}
@@ -0,0 +1,337 @@
+package xeger
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithCaptureTemplate(t *testing.T) {
+	iRe, err := NewInverseRegex(`app-(?P<version>[0-9]+\.[0-9]+)-[a-z]{4}`, WithSeed(1), WithCaptureTemplate("version", "1.25"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(s, "app-1.25-") {
+			t.Fatalf("got %q, want the pinned version", s)
+		}
+		seen[s] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected the rest of the pattern to vary")
+	}
+}
+
+func TestWithCaptureTemplateErrors(t *testing.T) {
+	var tests = []struct {
+		Name, Value string
+	}{
+		{"version", "one.two"},
+		{"missing", "1.0"},
+		{"", "1.0"},
+	}
+
+	for _, test := range tests {
+		_, err := NewInverseRegex(`v(?P<version>[0-9]+\.[0-9]+)(x)`, WithCaptureTemplate(test.Name, test.Value))
+		if err == nil {
+			t.Errorf("%q=%q: expected an error", test.Name, test.Value)
+		}
+	}
+}
+
+func TestGenerateWithTemplate(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<scheme>https?)://(?P<host>[a-z]{3,8}\.com)/(?P<id>[0-9]{4})`, WithSeed(1), WithCaptureTemplate("scheme", "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateWithTemplate(map[string]string{"host": "api.com", "scheme": "https"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(s, "https://api.com/") || !iRe.regexp.MatchString(s) {
+			t.Fatalf("got %q, want the templated scheme and host", s)
+		}
+	}
+
+	// the per-call values must not stick to x
+	s, err := iRe.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "http://api.com/") {
+		t.Errorf("got %q after GenerateWithTemplate", s)
+	}
+
+	for _, values := range []map[string]string{{"host": "api.example.com"}, {"missing": "x"}, {"": "x"}} {
+		if _, err := iRe.GenerateWithTemplate(values); err == nil {
+			t.Errorf("%v: expected an error", values)
+		}
+	}
+}
+
+func TestGenerateInto(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<id>[0-9]+)-(?P<code>[a-z]{2})`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := regexp.MustCompile(`^ID_7-[a-z]{2}$`)
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateInto(map[string]string{"id": "ID_7"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !check.MatchString(s) {
+			t.Fatalf("got %q, want the id inserted verbatim", s)
+		}
+	}
+
+	for _, segments := range []map[string]string{{"missing": "x"}, {"": "x"}} {
+		if _, err := iRe.GenerateInto(segments); err == nil {
+			t.Errorf("%v: expected an error", segments)
+		}
+	}
+}
+
+func TestCaptureNames(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    []string
+	}{
+		{`abc`, nil},
+		{`(a)(b)`, nil},
+		{`(?P<first>a)(b)(?P<last>c)`, []string{"first", "last"}},
+		{`(?P<outer>x(?P<inner>y)z)|(?P<alt>w)`, []string{"outer", "inner", "alt"}},
+		{`(?P<rep>[0-9]){3}`, []string{"rep"}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got := iRe.CaptureNames()
+		if strings.Join(got, ",") != strings.Join(test.Want, ",") {
+			t.Errorf("%s: got %v, want %v", test.Pattern, got, test.Want)
+		}
+	}
+}
+
+func TestWithCaptureLength(t *testing.T) {
+	iRe, err := NewInverseRegex(`id=(?P<pin>[0-9]+);(?P<tag>[a-z]*)`, WithSeed(1), WithCaptureLength("pin", 4, 4), WithCaptureLength("tag", 12, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pin := s[len("id="):strings.Index(s, ";")]
+		tag := s[strings.Index(s, ";")+1:]
+		if len(pin) != 4 {
+			t.Fatalf("%q: pin %q is not 4 digits", s, pin)
+		}
+		if len(tag) < 12 || len(tag) > 20 {
+			t.Fatalf("%q: tag %q is not 12 to 20 letters", s, tag)
+		}
+	}
+}
+
+func TestWithCaptureLengthErrors(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		Min, Max int
+	}{
+		{"pin", 5, 6},
+		{"pin", 3, 2},
+		{"missing", 1, 2},
+	}
+
+	for _, test := range tests {
+		_, err := NewInverseRegex(`(?P<pin>[0-9]{2,4})`, WithCaptureLength(test.Name, test.Min, test.Max))
+		if err == nil {
+			t.Errorf("%q [%d, %d]: expected an error", test.Name, test.Min, test.Max)
+		}
+	}
+	_, err := NewInverseRegex(`(?P<pin>[0-9]{2,4})`, WithCaptureLength("pin", 5, 6))
+	if !errors.Is(err, ErrLengthInfeasible) {
+		t.Errorf("got %v, want ErrLengthInfeasible", err)
+	}
+}
+
+func TestWithCaptureMarkers(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`(ab)(cd)`, "<ab><cd>"},
+		{`x(?:y)(z)`, "xy<z>"},
+		{`(a(b)c)`, "<a<b>c>"},
+		{`(?P<v>1\.0)-(q)`, "<1.0>-<q>"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithCaptureMarkers("<", ">"))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got, err := iRe.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+
+	iRe, err := NewInverseRegex(`(ab)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := iRe.Generate(); got != "ab" {
+		t.Errorf("got %q, want no markers by default", got)
+	}
+}
+
+func TestGenerateWithCaptures(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<user>[a-z]{3,6})@(?P<host>[a-z]{4})\.com`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, caps, err := iRe.GenerateWithCaptures()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := iRe.regexp.FindStringSubmatch(s)
+		if m == nil {
+			t.Fatalf("%q does not match", s)
+		}
+		for _, name := range iRe.CaptureNames() {
+			if want := m[iRe.regexp.SubexpIndex(name)]; caps[name] != want {
+				t.Errorf("%q: capture %s = %q, want %q", s, name, caps[name], want)
+			}
+		}
+	}
+}
+
+func TestGenerateWithCapturesUnnamed(t *testing.T) {
+	iRe, err := NewInverseRegex(`(\d+)-(?P<label>\w+)(?:,(\d))?`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, caps, err := iRe.GenerateWithCaptures()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := iRe.regexp.FindStringSubmatch(s)
+		if m == nil {
+			t.Fatalf("%q does not match", s)
+		}
+		if caps["1"] != m[1] || caps["label"] != m[2] {
+			t.Errorf("%q: got captures %v, want 1 = %q and label = %q", s, caps, m[1], m[2])
+		}
+		if _, ok := caps["2"]; ok {
+			t.Errorf("%q: named group also keyed by number in %v", s, caps)
+		}
+		if v, ok := caps["3"]; ok != strings.Contains(s, ",") || v != m[3] {
+			t.Errorf("%q: capture 3 = %q, want %q", s, v, m[3])
+		}
+	}
+}
+
+func TestGenerateWithCapturesLastOccurrence(t *testing.T) {
+	iRe, err := NewInverseRegex(`((?P<x>[0-9])){2}`, WithSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, caps, err := iRe.GenerateWithCaptures()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s) != 2 {
+			t.Fatalf("got %q, want two digits", s)
+		}
+		if caps["x"] != s[1:] {
+			t.Errorf("%q: capture x = %q, want the last digit", s, caps["x"])
+		}
+		if m := iRe.regexp.FindStringSubmatch(s); m[iRe.regexp.SubexpIndex("x")] != caps["x"] {
+			t.Errorf("%q: capture x = %q, regexp reports %q", s, caps["x"], m[2])
+		}
+	}
+}
+
+func TestGenerateWithCapturesSkippedGroup(t *testing.T) {
+	iRe, err := NewInverseRegex(`a(?:(?P<opt>b))?`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		s, caps, err := iRe.GenerateWithCaptures()
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, ok := caps["opt"]
+		if ok != (s == "ab") || (ok && v != "b") {
+			t.Fatalf("%q: got captures %v", s, caps)
+		}
+	}
+}
+
+func TestGenerateWithCapturesLengthRetry(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<n>(?P<in>x)?y*)`, WithSeed(1), WithCaptureLength("n", 2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		s, caps, err := iRe.GenerateWithCaptures()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// attempts rejected for their length must leave no captures behind
+		if _, ok := caps["in"]; ok != strings.HasPrefix(s, "x") {
+			t.Fatalf("%q: got captures %v", s, caps)
+		}
+	}
+}
+
+func TestGenerateTemplate(t *testing.T) {
+	iRe, err := NewInverseRegex(`GET /users/(?P<id>[0-9]+)/posts/(?P<postId>[0-9]+)(\?page=[0-9])?`, WithSeed(1), WithMustNotMatch(regexp.MustCompile(`GET`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	brace := func(name string) string { return "{" + name + "}" }
+	sawQuery := false
+	for i := 0; i < 20; i++ {
+		s := iRe.GenerateTemplate(brace)
+		rest, ok := strings.CutPrefix(s, "GET /users/{id}/posts/{postId}")
+		if !ok {
+			t.Fatalf("got %q", s)
+		}
+		if rest != "" {
+			sawQuery = true
+			if len(rest) != len("?page=0") || !strings.HasPrefix(rest, "?page=") {
+				t.Fatalf("unnamed group gave %q", rest)
+			}
+		}
+	}
+	if !sawQuery {
+		t.Errorf("the unnamed optional group was never generated")
+	}
+
+	iRe, err = NewInverseRegex(`(?P<a>x)(?P<a>y)`)
+	if err == nil {
+		if s := iRe.GenerateTemplate(brace); s != "{a}{a}" {
+			t.Errorf("got %q, want {a}{a}", s)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package xeger
+
+import (
+	"regexp/syntax"
+	"unicode"
+)
+
+// nonSurrogates is every rune except the UTF-16 surrogate halves, which
+// have no UTF-8 encoding and so can never be part of a match.
+var nonSurrogates = negateRanges([]rune{0xd800, 0xdfff})
+
+// negateRanges returns the complement within [0, unicode.MaxRune] of
+// ranges, a sorted list of disjoint inclusive lo, hi pairs as stored in a
+// char class. The result is the gaps between consecutive ranges, plus the
+// space before the first range unless it starts at 0 and the space after
+// the last unless it ends at unicode.MaxRune.
+func negateRanges(ranges []rune) []rune {
+	var out []rune
+	next := rune(0)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] > next {
+			out = append(out, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		out = append(out, next, unicode.MaxRune)
+	}
+	return out
+}
+
+// intersectRanges returns the runes in both a and b, which are sorted lists
+// of disjoint inclusive lo, hi pairs.
+func intersectRanges(a, b []rune) []rune {
+	var out []rune
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		lo, hi := a[i], a[i+1]
+		if b[j] > lo {
+			lo = b[j]
+		}
+		if b[j+1] < hi {
+			hi = b[j+1]
+		}
+		if lo <= hi {
+			out = append(out, lo, hi)
+		}
+		if a[i+1] < b[j+1] {
+			i += 2
+		} else {
+			j += 2
+		}
+	}
+	return out
+}
+
+// unionRanges returns the runes in a or b, which are sorted lists of
+// disjoint inclusive lo, hi pairs, as the complement of the runes in
+// neither.
+func unionRanges(a, b []rune) []rune {
+	return negateRanges(intersectRanges(negateRanges(a), negateRanges(b)))
+}
+
+// dropSurrogates removes the surrogate halves from every char class in re.
+// The parser writes a negated class such as [^aeiou] as the gaps around
+// the excluded runes, and those gaps span the surrogate block; picking
+// from it would emit U+FFFD instead of the chosen rune.
+func dropSurrogates(re *syntax.Regexp) {
+	if re.Op == syntax.OpCharClass {
+		re.Rune = intersectRanges(re.Rune, nonSurrogates)
+	}
+	for _, sub := range re.Sub {
+		dropSurrogates(sub)
+	}
+}
+
+// maxSpaceClass bounds the size of a class spaceOnly will inspect; no
+// larger class can consist only of whitespace.
+const maxSpaceClass = 32
+
+// spaceOnly reports whether every rune in ranges is whitespace.
+func spaceOnly(ranges []rune) bool {
+	if classSize(ranges) > maxSpaceClass {
+		return false
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if !unicode.IsSpace(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inRanges reports whether r falls within one of ranges.
+func inRanges(ranges []rune, r rune) bool {
+	for i := 0; i < len(ranges); i += 2 {
+		if r >= ranges[i] && r <= ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// linearClassRanges is the most ranges a char class can have for its runes
+// to be picked by scanning the ranges. Larger classes, such as \pL with
+// hundreds of ranges, get a table of cumulative sizes to binary search.
+const linearClassRanges = 8
+
+// classSizes adds to tables the cumulative range sizes of every char class
+// in re with more than linearClassRanges ranges: entry i is the number of
+// runes in ranges 0 to i.
+func classSizes(re *syntax.Regexp, tables map[*syntax.Regexp][]int64) {
+	if re.Op == syntax.OpCharClass && len(re.Rune) > 2*linearClassRanges {
+		sizes := make([]int64, len(re.Rune)/2)
+		var total int64
+		for i := range sizes {
+			total += int64(re.Rune[2*i+1]-re.Rune[2*i]) + 1
+			sizes[i] = total
+		}
+		tables[re] = sizes
+	}
+	for _, sub := range re.Sub {
+		classSizes(sub, tables)
+	}
+}
+
+// sizes returns the cumulative range sizes of the char class re, or nil if
+// it is small enough to scan.
+func (x *Xeger) sizes(re *syntax.Regexp) []int64 {
+	if len(re.Rune) <= 2*linearClassRanges {
+		return nil
+	}
+	return x.classSizes[re]
+}
+
+// nthRune returns rune n of ranges, counting from 0, given their
+// cumulative sizes.
+func nthRune(ranges []rune, sizes []int64, n int64) rune {
+	lo, hi := 0, len(sizes)-1
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if sizes[mid] > n {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	if lo > 0 {
+		n -= sizes[lo-1]
+	}
+	return ranges[2*lo] + rune(n)
+}
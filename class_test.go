@@ -0,0 +1,185 @@
+package xeger
+
+import (
+	"math/rand"
+	"reflect"
+	"regexp/syntax"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestNegateRanges(t *testing.T) {
+	const max = unicode.MaxRune
+	var tests = []struct {
+		Ranges []rune
+		Want   []rune
+	}{
+		{nil, []rune{0, max}},
+		{[]rune{0, max}, nil},
+		{[]rune{'a', 'a'}, []rune{0, 'a' - 1, 'a' + 1, max}},
+		{[]rune{0, 5}, []rune{6, max}},
+		{[]rune{5, max}, []rune{0, 4}},
+		{[]rune{0, 0, max, max}, []rune{1, max - 1}},
+		{[]rune{'a', 'a', 'e', 'e'}, []rune{0, 'a' - 1, 'b', 'd', 'f', max}},
+		{[]rune{'a', 'c', 'e', 'f'}, []rune{0, 'a' - 1, 'd', 'd', 'g', max}},
+	}
+
+	for _, test := range tests {
+		got := negateRanges(test.Ranges)
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("negateRanges(%v) = %v, want %v", test.Ranges, got, test.Want)
+		}
+		if back := negateRanges(got); !reflect.DeepEqual(back, test.Ranges) {
+			t.Errorf("negating %v twice gave %v", test.Ranges, back)
+		}
+	}
+}
+
+func TestIntersectRanges(t *testing.T) {
+	var tests = []struct {
+		A, B []rune
+		Want []rune
+	}{
+		{[]rune{'a', 'z'}, nil, nil},
+		{[]rune{'a', 'z'}, []rune{'m', 'p'}, []rune{'m', 'p'}},
+		{[]rune{'a', 'f', 'x', 'z'}, []rune{'d', 'y'}, []rune{'d', 'f', 'x', 'y'}},
+		{[]rune{'a', 'c'}, []rune{'d', 'f'}, nil},
+		{[]rune{0, unicode.MaxRune}, nonSurrogates, nonSurrogates},
+	}
+
+	for _, test := range tests {
+		if got := intersectRanges(test.A, test.B); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("intersectRanges(%v, %v) = %v, want %v", test.A, test.B, got, test.Want)
+		}
+	}
+}
+
+func TestNegatedClass(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+	}{
+		{`[^aeiou]`, nil},
+		{`[^\x00-\x{D7FF}]`, nil},
+		{`[^\x{FFFD}a]`, nil},
+		{`[^\x00-\x{FFFC}\x{FFFE}-\x{10FFFF}]`, nil}, // only U+FFFD
+		{`[^aeiou]`, []Option{WithEdgeBias(1)}},
+		{`[^\x00]`, []Option{WithEdgeBias(1)}},
+		{`[^\x{10FFFF}]`, []Option{WithEdgeBias(1)}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 500; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			r, _ := utf8.DecodeRuneInString(s)
+			if utf8.RuneCountInString(s) != 1 || !utf8.ValidString(s) || strings.ContainsRune("aeiou", r) {
+				t.Fatalf("%s: got %q", test.Pattern, s)
+			}
+		}
+	}
+}
+
+func TestSurrogateOnlyClass(t *testing.T) {
+	iRe, err := NewInverseRegex(`[\x{D800}-\x{DFFF}]`)
+	if err != nil {
+		t.Skipf("parser rejects surrogates: %v", err)
+	}
+	if err := iRe.CanGenerate(); err == nil {
+		t.Error("expected an error for a class of only surrogates")
+	}
+}
+
+func TestUnionRanges(t *testing.T) {
+	var tests = []struct {
+		A, B []rune
+		Want []rune
+	}{
+		{nil, nil, nil},
+		{[]rune{'a', 'c'}, nil, []rune{'a', 'c'}},
+		{[]rune{'a', 'c'}, []rune{'b', 'f'}, []rune{'a', 'f'}},
+		{[]rune{'a', 'c'}, []rune{'d', 'f'}, []rune{'a', 'f'}},
+		{[]rune{'a', 'a', 'x', 'z'}, []rune{'c', 'e'}, []rune{'a', 'a', 'c', 'e', 'x', 'z'}},
+		{[]rune{0, 10}, []rune{5, unicode.MaxRune}, []rune{0, unicode.MaxRune}},
+	}
+
+	for _, test := range tests {
+		if got := unionRanges(test.A, test.B); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("unionRanges(%v, %v) = %v, want %v", test.A, test.B, got, test.Want)
+		}
+	}
+}
+
+func TestNthRune(t *testing.T) {
+	re, err := syntax.Parse(`[\pL\pN]`, syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := make(map[*syntax.Regexp][]int64)
+	classSizes(re, tables)
+	sizes := tables[re]
+	if sizes == nil {
+		t.Fatalf("no table for a class of %d ranges", len(re.Rune)/2)
+	}
+	if total := sizes[len(sizes)-1]; total != classSize(re.Rune) {
+		t.Fatalf("table total %d, want %d", total, classSize(re.Rune))
+	}
+	var want []rune
+	for i := 0; i < len(re.Rune); i += 2 {
+		for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+			want = append(want, r)
+		}
+	}
+	for n, r := range want {
+		if got := nthRune(re.Rune, sizes, int64(n)); got != r {
+			t.Fatalf("nthRune(%d) = %U, want %U", n, got, r)
+		}
+	}
+}
+
+func TestDrawRuneSizesIdentical(t *testing.T) {
+	iRe, err := NewInverseRegex(`\pL`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := iRe.sizes(iRe.re)
+	if sizes == nil {
+		t.Fatal("no table for \\pL")
+	}
+	a := iRe.newGenerator(rand.New(rand.NewSource(1)))
+	b := iRe.newGenerator(rand.New(rand.NewSource(1)))
+	for i := 0; i < 1000; i++ {
+		if ra, rb := a.drawRune(iRe.re.Rune, sizes), b.drawRune(iRe.re.Rune, nil); ra != rb {
+			t.Fatalf("draw %d: table gave %U, scan gave %U", i, ra, rb)
+		}
+	}
+}
+
+func BenchmarkCharClassPick(b *testing.B) {
+	iRe, err := NewInverseRegex(`\pL`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	g := iRe.newGenerator(rand.New(rand.NewSource(1)))
+	ranges, sizes := iRe.re.Rune, iRe.sizes(iRe.re)
+	b.Run("precomputed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.drawRune(ranges, sizes)
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.drawRune(ranges, nil)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package xeger
+
+import "regexp/syntax"
+
+// A ClassPick records the rune chosen at one char class while generating
+// a string.
+type ClassPick struct {
+	// Site identifies the class by its position among the char classes
+	// of the pattern in the order they are written, so in [a-z]+@[0-9]
+	// [a-z] is site 0 and [0-9] site 1. Every repetition of a class picks
+	// at the same site.
+	Site int
+
+	// Ranges are the class's runes as inclusive lo, hi pairs, and Rune
+	// the one chosen from them.
+	Ranges []rune
+	Rune   rune
+
+	// Offset is the byte offset of the rune in the generated string.
+	Offset int
+}
+
+// GenerateWithClassPicks is like Generate but also returns every char
+// class pick made for the string, in output order. Tallying the picks of a
+// site over many calls shows whether options such as WithEdgeBias or
+// WithRuneWeights skew its distribution as intended. Picks made by attempts
+// rejected by the configured checks are not included. On failure it returns
+// an empty string and nil.
+func (x *Xeger) GenerateWithClassPicks() (string, []ClassPick) {
+	sites := make(map[*syntax.Regexp]int)
+	classSites(x.re, sites)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.classSites = sites
+	s, err := g.generate()
+	if err != nil {
+		return "", nil
+	}
+	return s, g.classPicks
+}
+
+// classSites numbers the char classes in re in preorder, continuing from
+// the classes already in sites.
+func classSites(re *syntax.Regexp, sites map[*syntax.Regexp]int) {
+	if re.Op == syntax.OpCharClass {
+		sites[re] = len(sites)
+	}
+	for _, sub := range re.Sub {
+		classSites(sub, sites)
+	}
+}
+
+// pickedClass records the rune just appended to the buffer from offset
+// start for the char class re.
+func (g *generator) pickedClass(re *syntax.Regexp, r rune, start int) {
+	g.classPicks = append(g.classPicks, ClassPick{
+		Site:   g.classSites[re],
+		Ranges: re.Rune,
+		Rune:   r,
+		Offset: start - g.matchStart,
+	})
+}
+
+// dropClassPicks discards the picks recorded from offset start of the
+// buffer onwards, for output that is being generated again.
+func (g *generator) dropClassPicks(start int) {
+	n := len(g.classPicks)
+	for n > 0 && g.classPicks[n-1].Offset >= start-g.matchStart {
+		n--
+	}
+	g.classPicks = g.classPicks[:n]
+}
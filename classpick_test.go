@@ -0,0 +1,64 @@
+package xeger
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerateWithClassPicks(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+	}{
+		{`[a-z]{2,5}@[0-9]x(?:[é-ë]|y)`, nil},
+		{`[a-c]+-[0-9]{2}`, []Option{WithSurroundingNoise(true)}},
+		{`(?P<n>[a-z]+)`, []Option{WithCaptureLength("n", 3, 3)}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 20; i++ {
+			s, picks := iRe.GenerateWithClassPicks()
+			if len(picks) == 0 {
+				t.Fatalf("%s: no picks for %q", test.Pattern, s)
+			}
+			for j, p := range picks {
+				if j > 0 && p.Offset <= picks[j-1].Offset {
+					t.Errorf("%s: pick %d at %d after %d", test.Pattern, j, p.Offset, picks[j-1].Offset)
+				}
+				if r, _ := utf8.DecodeRuneInString(s[p.Offset:]); r != p.Rune || !inRanges(p.Ranges, r) {
+					t.Errorf("%s: pick %v does not match %q", test.Pattern, p, s)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateWithClassPicksSites(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{3}@[0-9]`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, picks := iRe.GenerateWithClassPicks()
+	var sites []int
+	for _, p := range picks {
+		sites = append(sites, p.Site)
+	}
+	if len(sites) != 4 || sites[0] != 0 || sites[2] != 0 || sites[3] != 1 {
+		t.Errorf("got sites %v, want [0 0 0 1]", sites)
+	}
+
+	// a tally over many calls reflects the configured bias
+	iRe, err = NewInverseRegex(`[a-z]`, WithSeed(1), WithEdgeBias(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if _, picks := iRe.GenerateWithClassPicks(); picks[0].Rune != 'a' && picks[0].Rune != 'z' {
+			t.Fatalf("edge bias of 1 picked %q", picks[0].Rune)
+		}
+	}
+}
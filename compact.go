@@ -0,0 +1,45 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// WithCompactBias pushes every random choice towards shorter output, as a
+// continuous knob between typical and minimal strings. With strength s in
+// [0, 1], quantifiers choose their counts from the lowest 1-s fraction of
+// their range, so with s of 0.5 a{0,10} repeats at most 5 times; ? takes
+// its subpattern with its usual probability scaled by 1-s; and
+// alternations take the branch with the shortest possible match with
+// probability s, choosing as usual otherwise. At 0 generation is
+// unchanged, and at 1 every choice is the smallest, giving a shortest
+// string of the pattern up to ties between branches. It is an error if
+// strength is outside [0, 1].
+func WithCompactBias(strength float64) Option {
+	return func(x *Xeger) error {
+		if strength < 0 || strength > 1 {
+			return fmt.Errorf("xeger: compact bias must be in [0, 1], got %v", strength)
+		}
+		x.compact = strength
+		x.shortest = make(map[*syntax.Regexp]int)
+		shortestBranches(x.re, x.shortest)
+		return nil
+	}
+}
+
+// shortestBranches records in shortest, for each alternation in re, the
+// first of its branches with the least minimum length.
+func shortestBranches(re *syntax.Regexp, shortest map[*syntax.Regexp]int) {
+	if re.Op == syntax.OpAlternate {
+		best, bestLen := 0, -1
+		for i, sub := range re.Sub {
+			if n, _ := lengthBounds(sub); bestLen == -1 || n < bestLen {
+				best, bestLen = i, n
+			}
+		}
+		shortest[re] = best
+	}
+	for _, sub := range re.Sub {
+		shortestBranches(sub, shortest)
+	}
+}
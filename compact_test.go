@@ -0,0 +1,40 @@
+package xeger
+
+import "testing"
+
+func TestWithCompactBias(t *testing.T) {
+	const pattern = `(?:[a-z]{2,12}|x)(?:-[0-9]+)*(?:\.html)?`
+	mean := func(strength float64) float64 {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1), WithCompactBias(strength))
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", strength, err)
+		}
+		total := 0
+		for i := 0; i < 500; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%v: %v", strength, err)
+			}
+			total += len(s)
+		}
+		return float64(total) / 500
+	}
+
+	prev := mean(0)
+	for _, strength := range []float64{0.5, 0.9} {
+		m := mean(strength)
+		if m >= prev {
+			t.Errorf("strength %v: mean length %.2f, not below %.2f", strength, m, prev)
+		}
+		prev = m
+	}
+	if m := mean(1); m != 1 {
+		t.Errorf("strength 1: mean length %.2f, want the minimal 1", m)
+	}
+
+	for _, strength := range []float64{-0.1, 1.5} {
+		if _, err := NewInverseRegex(pattern, WithCompactBias(strength)); err == nil {
+			t.Errorf("%v: expected an error", strength)
+		}
+	}
+}
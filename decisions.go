@@ -0,0 +1,130 @@
+package xeger
+
+import "fmt"
+
+// A DecisionKind identifies what a Decision chose.
+type DecisionKind uint8
+
+const (
+	// DecisionBranch is the index of the branch taken at an alternation.
+	DecisionBranch DecisionKind = iota + 1
+	// DecisionRepeat is the count chosen for a quantifier, or the length
+	// of a run of surrounding noise.
+	DecisionRepeat
+	// DecisionRune is the rune chosen for a char class, a case-insensitive
+	// letter or a noise character.
+	DecisionRune
+)
+
+func (k DecisionKind) String() string {
+	switch k {
+	case DecisionBranch:
+		return "branch"
+	case DecisionRepeat:
+		return "repeat"
+	case DecisionRune:
+		return "rune"
+	}
+	return fmt.Sprintf("DecisionKind(%d)", uint8(k))
+}
+
+// A Decision is one random choice made during generation. A generated
+// string is fully determined by its pattern, options and the sequence of
+// decisions taken in the order the pattern is walked.
+type Decision struct {
+	Kind  DecisionKind `json:"k"`
+	Value int          `json:"v"`
+}
+
+// GenerateWithDecisions is like Generate but also returns the decisions
+// that produced the string, which ReplayDecisions turns back into the same
+// string regardless of seed. Decisions made by attempts rejected by the
+// configured checks are not included. On failure it returns an empty
+// string and nil.
+func (x *Xeger) GenerateWithDecisions() (string, []Decision) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.recording = true
+	s, err := g.generate()
+	if err != nil {
+		return "", nil
+	}
+	return s, g.decisions
+}
+
+// ReplayDecisions regenerates a string from a decision log such as one
+// returned by GenerateWithDecisions, drawing nothing from any random
+// source. A log that runs out early is completed with the smallest choice
+// at each remaining decision: the first branch, the minimum count and the
+// lowest rune. It returns an error wrapping ErrBadDecisions if a decision
+// does not fit the pattern where it is used, or if the result fails the
+// configured checks.
+func (x *Xeger) ReplayDecisions(decisions []Decision) (string, error) {
+	s, _, err := x.replay(decisions)
+	return s, err
+}
+
+// replay regenerates a string from decisions, also returning the full log
+// of decisions used, including those filled in past the end of decisions.
+func (x *Xeger) replay(decisions []Decision) (string, []Decision, error) {
+	g := x.newGenerator(nil)
+	g.recording = true
+	g.replaying = true
+	g.replay = decisions
+	b, err := g.appendOnce(nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if s := string(b); !x.accept(s) {
+		return "", nil, fmt.Errorf("%w: %q fails the configured checks", ErrBadDecisions, s)
+	}
+	return string(x.encode(b, 0)), g.decisions, nil
+}
+
+// decide makes a decision of kind k. Normally the value is drawn by draw;
+// when replaying it is the next logged decision, which must be of kind k
+// and accepted by valid, or dflt once the log is exhausted. The value is
+// logged if recording.
+func (g *generator) decide(k DecisionKind, dflt int, draw func() int, valid func(int) bool) (int, error) {
+	var v int
+	switch {
+	case !g.replaying:
+		v = draw()
+	case g.replayPos == len(g.replay):
+		v = dflt
+	default:
+		d := g.replay[g.replayPos]
+		if d.Kind != k || !valid(d.Value) {
+			return 0, fmt.Errorf("%w: decision %d is %s %d, want a valid %s", ErrBadDecisions, g.replayPos, d.Kind, d.Value, k)
+		}
+		g.replayPos++
+		v = d.Value
+	}
+	if g.recording {
+		g.decisions = append(g.decisions, Decision{Kind: k, Value: v})
+	}
+	return v, nil
+}
+
+// mark returns the position of the walk in the decision log, for rewind.
+// Replays always record, so while replaying the position is also an index
+// into the replay log, or past its end once it is exhausted.
+func (g *generator) mark() int {
+	return len(g.decisions)
+}
+
+// rewind discards the decisions logged since mark was taken and, when
+// replaying, rereads the replay log from there, so that a part of the walk
+// can be retried.
+func (g *generator) rewind(mark int) {
+	if g.recording {
+		g.decisions = g.decisions[:mark]
+	}
+	if g.replaying {
+		g.replayPos = mark
+		if g.replayPos > len(g.replay) {
+			g.replayPos = len(g.replay)
+		}
+	}
+}
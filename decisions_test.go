@@ -0,0 +1,103 @@
+package xeger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReplayDecisions(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+	}{
+		{`(foo|bar|qux)-[a-z]{2,6}[0-9]*`, nil},
+		{`(?i)hello [^aeiou]+`, []Option{WithFoldStrategy(FoldRandom)}},
+		{`(?P<pin>[0-9]+)-x?`, []Option{WithCaptureLength("pin", 4, 4)}},
+		{`[a-z]{3}`, []Option{WithSurroundingNoise(true)}},
+		{`(a|b|c){1,5}`, []Option{WithDistinctAlternatesInRepeat(true)}},
+		{`[a-z]{1,10}`, []Option{WithLengthRange(8, 10)}},
+		{`[a-z ]{2,4}/[a-z]`, []Option{WithURLEncode(URLQuery)}},
+	}
+
+	for _, test := range tests {
+		gen, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		replay, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(99))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 20; i++ {
+			s, decisions := gen.GenerateWithDecisions()
+			if s == "" || len(decisions) == 0 {
+				t.Fatalf("%s: got %q with decisions %v", test.Pattern, s, decisions)
+			}
+			b, err := json.Marshal(decisions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back []Decision
+			if err := json.Unmarshal(b, &back); err != nil {
+				t.Fatal(err)
+			}
+			got, err := replay.ReplayDecisions(back)
+			if err != nil {
+				t.Fatalf("%s: replaying %s: %v", test.Pattern, b, err)
+			}
+			if got != s {
+				t.Fatalf("%s: replaying %s gave %q, want %q", test.Pattern, b, got, s)
+			}
+		}
+	}
+}
+
+func TestReplayDecisionsExhausted(t *testing.T) {
+	iRe, err := NewInverseRegex(`(foo|bar|qux)-[a-z]{2,6}[0-9]*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		Decisions []Decision
+		Want      string
+	}{
+		{nil, "foo-aa"},
+		{[]Decision{{DecisionBranch, 2}}, "qux-aa"},
+		{[]Decision{{DecisionBranch, 1}, {DecisionRepeat, 3}, {DecisionRune, 'x'}}, "bar-xaa"},
+	}
+	for _, test := range tests {
+		got, err := iRe.ReplayDecisions(test.Decisions)
+		if err != nil {
+			t.Fatalf("%v: %v", test.Decisions, err)
+		}
+		if got != test.Want {
+			t.Errorf("%v: got %q, want %q", test.Decisions, got, test.Want)
+		}
+	}
+}
+
+func TestReplayBadDecisions(t *testing.T) {
+	iRe, err := NewInverseRegex(`(foo|bar)[a-c]{2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decisions := range [][]Decision{
+		{{DecisionRepeat, 0}},
+		{{DecisionBranch, 2}},
+		{{DecisionBranch, 0}, {DecisionRepeat, 3}},
+		{{DecisionBranch, 0}, {DecisionRepeat, 2}, {DecisionRune, 'z'}},
+	} {
+		if s, err := iRe.ReplayDecisions(decisions); !errors.Is(err, ErrBadDecisions) {
+			t.Errorf("%v: got %q, %v, want ErrBadDecisions", decisions, s, err)
+		}
+	}
+
+	iRe, err = NewInverseRegex(`[a-z]{1,10}`, WithLengthRange(8, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := iRe.ReplayDecisions(nil); !errors.Is(err, ErrBadDecisions) {
+		t.Errorf("got %q, %v, want a failed check", s, err)
+	}
+}
@@ -0,0 +1,45 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// WithStructuralDepth bounds how deeply the quantifiers of a generated
+// string nest, for feeding a recursive parser structures of known depth.
+// Regular expressions cannot nest without limit, so a bracket structure is
+// written out level by level, as in \[(?:[a-z]|\[(?:[a-z]|\[[a-z]*\])*\])*\],
+// where each level of brackets sits inside one more quantifier. With depth
+// n, quantifiers nested inside n others or more, counting ?, * and + as
+// quantifiers, repeat their minimum number of times, so with n of 1 the
+// pattern above nests brackets at most two deep and with n of 0 gives "[]".
+// Quantifiers within the depth choose their counts as usual, still capped
+// by WithMaxReps. A deep quantifier with a minimum above zero, such as
+// a +, still generates that minimum. The depth takes precedence over
+// WithIncludeAllOptionals, while WithExactRepeat takes precedence over
+// both. It is an error if n is negative.
+func WithStructuralDepth(n int) Option {
+	return func(x *Xeger) error {
+		if n < 0 {
+			return fmt.Errorf("xeger: structural depth must not be negative, got %d", n)
+		}
+		x.tooDeep = make(map[*syntax.Regexp]bool)
+		deepQuantifiers(x.re, n, x.tooDeep)
+		return nil
+	}
+}
+
+// deepQuantifiers records in deep the quantifiers of re nested inside at
+// least n others.
+func deepQuantifiers(re *syntax.Regexp, n int, deep map[*syntax.Regexp]bool) {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if n <= 0 {
+			deep[re] = true
+		}
+		n--
+	}
+	for _, sub := range re.Sub {
+		deepQuantifiers(sub, n, deep)
+	}
+}
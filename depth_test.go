@@ -0,0 +1,50 @@
+package xeger
+
+import "testing"
+
+// bracketDepth returns how deeply the brackets of s nest.
+func bracketDepth(s string) int {
+	depth, deepest := 0, 0
+	for _, r := range s {
+		switch r {
+		case '[':
+			depth++
+			deepest = max(deepest, depth)
+		case ']':
+			depth--
+		}
+	}
+	return deepest
+}
+
+func TestWithStructuralDepth(t *testing.T) {
+	const pattern = `\[(?:[a-z]|\[(?:[a-z]|\[(?:[a-z]|\[[a-z]*\])*\])*\])*\]`
+	for n := 0; n <= 3; n++ {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1), WithStructuralDepth(n), WithIncludeAllOptionals(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deepest := 0
+		for i := 0; i < 200; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := bracketDepth(s); d > n+1 {
+				t.Fatalf("depth %d: %q nests %d deep", n, s, d)
+			} else {
+				deepest = max(deepest, d)
+			}
+			if n == 0 && s != "[]" {
+				t.Fatalf("depth 0: got %q, want []", s)
+			}
+		}
+		if deepest != n+1 {
+			t.Errorf("depth %d: brackets nested at most %d deep, want %d", n, deepest, n+1)
+		}
+	}
+
+	if _, err := NewInverseRegex(pattern, WithStructuralDepth(-1)); err == nil {
+		t.Error("expected an error for a negative depth")
+	}
+}
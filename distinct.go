@@ -0,0 +1,150 @@
+package xeger
+
+import "regexp/syntax"
+
+// maxDealAttempts bounds how many times a char class pick is redrawn while
+// looking for a rune not yet dealt, for classes too large to scan.
+const maxDealAttempts = 32
+
+// maxScannedClass is the largest char class whose unused runes are found by
+// scanning rather than redrawing.
+const maxScannedClass = 1024
+
+// A dealer remembers which choices at one node have been used up within a
+// repeat or batch, so they can be dealt without replacement.
+type dealer struct {
+	branches []bool
+	runes    map[rune]bool
+	counts   map[int]bool
+	n        int
+}
+
+// dealerFor returns the dealer in dealt for re, creating it on first use.
+func dealerFor(dealt map[*syntax.Regexp]*dealer, re *syntax.Regexp) *dealer {
+	d := dealt[re]
+	if d == nil {
+		d = &dealer{}
+		dealt[re] = d
+	}
+	return d
+}
+
+// dealBranch picks a branch of the alternation re that has not been chosen
+// yet according to dealt, such as within the current repeat. Once every
+// branch has been used the set is reset, so repeats longer than the branch
+// count revisit branches.
+func (g *generator) dealBranch(dealt map[*syntax.Regexp]*dealer, re *syntax.Regexp) int {
+	d := dealerFor(dealt, re)
+	if d.n == len(re.Sub) || d.branches == nil {
+		d.branches = make([]bool, len(re.Sub))
+		d.n = 0
+	}
+	k := g.rng.Intn(len(re.Sub) - d.n)
+	for i, used := range d.branches {
+		if used {
+			continue
+		}
+		if k == 0 {
+			d.branches[i] = true
+			d.n++
+			return i
+		}
+		k--
+	}
+	panic("unreachable")
+}
+
+// dealRune picks a rune from the char class re that has not been chosen yet
+// according to dealt. The parser folds single-rune alternations such as
+// a|b|c into char classes, so within repeats this gives them the same
+// treatment as dealBranch. Runes the generator does not allow are never
+// dealt. Large classes fall back to a plain pick when no unused rune turns
+// up within a few draws.
+func (g *generator) dealRune(dealt map[*syntax.Regexp]*dealer, re *syntax.Regexp) rune {
+	d := dealerFor(dealt, re)
+	size := classSize(re.Rune)
+	if d.runes == nil || int64(len(d.runes)) == size {
+		d.runes = make(map[rune]bool)
+	}
+	var r rune
+	if size <= maxScannedClass {
+		n := d.countUnused(re.Rune, g.allow)
+		if n == 0 && len(d.runes) > 0 {
+			// every allowed rune has been dealt
+			d.runes = make(map[rune]bool)
+			n = d.countUnused(re.Rune, g.allow)
+		}
+		if n == 0 {
+			return g.pickRune(re.Rune, g.x.sizes(re))
+		}
+		r = d.unusedRune(re.Rune, g.allow, g.rng.Int63n(n))
+	} else {
+		r = g.pickRune(re.Rune, g.x.sizes(re))
+		for i := 0; i < maxDealAttempts && d.runes[r]; i++ {
+			r = g.pickRune(re.Rune, g.x.sizes(re))
+		}
+	}
+	d.runes[r] = true
+	return r
+}
+
+// dealCount chooses a count for the quantifier re, repeating between min
+// and max times, that has not been chosen yet according to dealt. Counts
+// are drawn as usual and redrawn a few times while they repeat an earlier
+// one, so the rep strategy still shapes them. Once every count has been
+// used the set is reset.
+func (g *generator) dealCount(dealt map[*syntax.Regexp]*dealer, re *syntax.Regexp, min, max int) int {
+	d := dealerFor(dealt, re)
+	if d.counts == nil || len(d.counts) > g.countLimit(min, max)-min {
+		d.counts = make(map[int]bool)
+	}
+	n := g.drawCount(re, min, max)
+	for i := 0; i < maxDealAttempts && d.counts[n]; i++ {
+		n = g.drawCount(re, min, max)
+	}
+	d.counts[n] = true
+	return n
+}
+
+// countUnused returns the number of runes of ranges that d has not dealt
+// yet and that allow, if non-nil, accepts.
+func (d *dealer) countUnused(ranges []rune, allow func(rune) bool) int64 {
+	if allow == nil {
+		return classSize(ranges) - int64(len(d.runes))
+	}
+	var n int64
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if !d.runes[r] && allow(r) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// unusedRune returns the k'th rune of ranges that d has not dealt yet and
+// that allow, if non-nil, accepts.
+func (d *dealer) unusedRune(ranges []rune, allow func(rune) bool, k int64) rune {
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if d.runes[r] || allow != nil && !allow(r) {
+				continue
+			}
+			if k == 0 {
+				return r
+			}
+			k--
+		}
+	}
+	panic("unreachable")
+}
+
+// classSize returns the number of runes in a char class's ranges.
+func classSize(ranges []rune) int64 {
+	var total int64
+	for i := 0; i < len(ranges); i += 2 {
+		total += int64(ranges[i+1]-ranges[i]) + 1
+	}
+	return total
+}
@@ -0,0 +1,154 @@
+package xeger
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDistinctAlternatesInRepeat(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    []string
+	}{
+		{`(a|b|c){3}`, []string{"a", "b", "c"}},
+		{`(foo|bar|qux){3}`, []string{"bar", "foo", "qux"}},
+		{`(?:x|yy|zzz){3}`, []string{"x", "yy", "zzz"}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithDistinctAlternatesInRepeat(true))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if got := splitBranches(s, test.Want); strings.Join(got, ",") != strings.Join(test.Want, ",") {
+				t.Fatalf("%s: %q does not use each branch once", test.Pattern, s)
+			}
+		}
+	}
+}
+
+func TestDistinctAlternatesFallback(t *testing.T) {
+	iRe, err := NewInverseRegex(`(x|y){5}`, WithSeed(1), WithDistinctAlternatesInRepeat(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := strings.Count(s, "x") - strings.Count(s, "y"); d > 1 || d < -1 {
+			t.Fatalf("%q: expected branches to be dealt evenly", s)
+		}
+	}
+}
+
+// splitBranches breaks s into the branches it was built from, sorted.
+func splitBranches(s string, branches []string) []string {
+	var out []string
+	for s != "" {
+		found := false
+		for _, b := range branches {
+			if strings.HasPrefix(s, b) {
+				out = append(out, b)
+				s = s[len(b):]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestWithCoverageBias(t *testing.T) {
+	iRe, err := NewInverseRegex(`(alpha|beta|gamma|delta|epsilon)-(one|two|three)`, WithSeed(1), WithCoverageBias(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := make(map[string]bool)
+	second := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts := strings.SplitN(s, "-", 2)
+		first[parts[0]] = true
+		second[parts[1]] = true
+	}
+	if len(first) != 5 || len(second) != 3 {
+		t.Errorf("five calls covered only %v and %v", first, second)
+	}
+
+	// calls with their own random source are left alone
+	a, _ := iRe.GenerateAt(3)
+	b, _ := iRe.GenerateAt(3)
+	if a != b {
+		t.Errorf("GenerateAt(3) gave %q then %q", a, b)
+	}
+
+	// GenerateWith leaves the coverage state alone even when handed the
+	// instance's own source
+	fresh, err := NewInverseRegex(`(alpha|beta|gamma)`, WithSeed(1), WithCoverageBias(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := fresh.GenerateWith(fresh.rng); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fresh.coverage) != 0 {
+		t.Errorf("GenerateWith dealt from %d coverage dealers", len(fresh.coverage))
+	}
+}
+
+func TestWithMaximizeVariety(t *testing.T) {
+	// each copy of the outer repeat starts a new inner repeat, so only
+	// tracking across the whole string keeps the copies apart
+	iRe, err := NewInverseRegex(`(?:(?:(alpha|beta|gamma|delta);){2}-){2}`, WithSeed(1), WithMaximizeVariety(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '-' }) {
+			seen[w] = true
+		}
+		if len(seen) != 4 {
+			t.Errorf("%q: got %d distinct words, want 4", s, len(seen))
+		}
+	}
+
+	iRe, err = NewInverseRegex(`(?:x[0-9]{1,4},){4}`, WithSeed(1), WithMaximizeVariety(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths := make(map[int]bool)
+		for _, part := range strings.Split(strings.TrimSuffix(s, ","), ",") {
+			lengths[len(part)] = true
+		}
+		if len(lengths) != 4 {
+			t.Errorf("%q: repeated a count in %v", s, lengths)
+		}
+	}
+}
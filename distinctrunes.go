@@ -0,0 +1,59 @@
+package xeger
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// WithMaxDistinctRunes caps how many distinct runes a generated string
+// uses, for shaping data for compression or alphabet-constrained systems.
+// Once k distinct runes have appeared, char class and . picks choose among
+// the runes already used that the class allows, so [a-z]{100} with k of 3
+// uses at most three letters. It is a preference: a pick whose class holds
+// none of the used runes, such as the [0-9] of [a-z]{3}[0-9], and
+// literals, which have no choice, still add new runes. It is an error if
+// k is not positive.
+func WithMaxDistinctRunes(k int) Option {
+	return func(x *Xeger) error {
+		if k < 1 {
+			return fmt.Errorf("xeger: max distinct runes must be positive, got %d", k)
+		}
+		x.maxDistinct = k
+		return nil
+	}
+}
+
+// reuseRune returns r, a rune picked from ranges, unless the string
+// already has the maximum number of distinct runes and r is not one of
+// them, in which case it returns a random used rune in ranges instead.
+func (g *generator) reuseRune(ranges []rune, r rune) rune {
+	if g.seenUpTo > len(g.buf) {
+		// a retry truncated the output, so recount from the start
+		for c := range g.seen {
+			delete(g.seen, c)
+		}
+		g.seenUpTo = g.matchStart
+	}
+	for b := g.buf[g.seenUpTo:]; len(b) > 0; {
+		c, n := utf8.DecodeRune(b)
+		g.seen[c] = true
+		b = b[n:]
+	}
+	g.seenUpTo = len(g.buf)
+	if g.seen[r] || len(g.seen) < g.x.maxDistinct {
+		return r
+	}
+	var used []rune
+	for c := range g.seen {
+		if inRanges(ranges, c) && (g.allow == nil || g.allow(c)) {
+			used = append(used, c)
+		}
+	}
+	if len(used) == 0 {
+		return r
+	}
+	// sorted, as map order would make seeded output vary
+	slices.Sort(used)
+	return used[g.rng.Intn(len(used))]
+}
@@ -0,0 +1,40 @@
+package xeger
+
+import "testing"
+
+func TestWithMaxDistinctRunes(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		K       int
+		Max     int
+	}{
+		{`[a-z]{100}`, 3, 3},
+		{`.{50}`, 1, 1},
+		{`[a-z]{20}-[0-9]{20}`, 2, 4},
+		{`x[a-z]{30}`, 4, 4},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithMaxDistinctRunes(test.K))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 20; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			distinct := make(map[rune]bool)
+			for _, r := range s {
+				distinct[r] = true
+			}
+			if len(distinct) > test.Max {
+				t.Fatalf("%s: %q has %d distinct runes, want at most %d", test.Pattern, s, len(distinct), test.Max)
+			}
+		}
+	}
+
+	if _, err := NewInverseRegex(`a`, WithMaxDistinctRunes(0)); err == nil {
+		t.Error("expected an error for k of 0")
+	}
+}
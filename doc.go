@@ -0,0 +1,34 @@
+// Package xeger generates strings matched by a regular expression, the
+// inverse of matching with package regexp.
+//
+// # Reproducibility
+//
+// A Xeger built WithSeed produces the same sequence of strings on every
+// run and with every Go release, as math/rand guarantees the sequence of
+// a seeded source. What a seed yields also depends on the order in which
+// the generator draws from that source, which is fixed as follows.
+//
+// The parsed pattern is walked depth first, left to right, as it is
+// written. Each node draws, at the moment the walk reaches it and before
+// walking any of its children: an alternation draws its branch; a
+// quantifier draws its count and then walks that many copies in turn, each
+// drawing afresh; a char class draws its rune, first drawing whether to
+// apply any configured edge bias or weights; a case-insensitive literal
+// draws each rune's case under FoldRandom; and a . draws its rune, after
+// drawing whether to emit a newline if it can match one. Surrounding noise
+// is drawn after the match, first the prefix then the suffix. A string
+// rejected by the configured checks is regenerated from where the source
+// left off, so retries consume further draws.
+//
+// Changing this order changes seeded output, and is treated as a breaking
+// change; the golden tests pin it.
+//
+// # Transformed output
+//
+// WithRegexSafe, WithURLEncode and WithShellSafe turn each generated
+// string into an escaped, percent-encoded or quoted form, applied in that
+// order once the configured checks, such as WithLengthRange, have accepted
+// the plain string. Transformed output, like that of WithCaptureMarkers,
+// generally no longer matches the pattern, so these options should not be
+// combined with GenerateValid or other matching checks.
+package xeger
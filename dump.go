@@ -0,0 +1,126 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// dumpFlags are the parse flags DumpTree reports, as the ones affecting
+// what a node generates.
+var dumpFlags = []struct {
+	flag syntax.Flags
+	name string
+}{
+	{syntax.FoldCase, "FoldCase"},
+	{syntax.DotNL, "DotNL"},
+	{syntax.NonGreedy, "NonGreedy"},
+	{syntax.WasDollar, "WasDollar"},
+}
+
+// DumpTree renders the parsed pattern as an indented tree, one node per
+// line, showing each node's op, runes, repeat bounds and relevant flags.
+// It is a debugging aid for understanding why a generated string looks the
+// way it does.
+func (x *Xeger) DumpTree() string {
+	var b strings.Builder
+	dumpNode(&b, x.re, 0)
+	return b.String()
+}
+
+// dumpNode writes re and its subexpressions to b at the given depth.
+func dumpNode(b *strings.Builder, re *syntax.Regexp, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(OpName(re.Op))
+	switch re.Op {
+	case syntax.OpLiteral:
+		fmt.Fprintf(b, " %q", string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteString(" [")
+		for i := 0; i < len(re.Rune); i += 2 {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			if re.Rune[i] == re.Rune[i+1] {
+				fmt.Fprintf(b, "%q", re.Rune[i])
+			} else {
+				fmt.Fprintf(b, "%q-%q", re.Rune[i], re.Rune[i+1])
+			}
+		}
+		b.WriteByte(']')
+	case syntax.OpCapture:
+		fmt.Fprintf(b, " #%d", re.Cap)
+		if re.Name != "" {
+			fmt.Fprintf(b, " %q", re.Name)
+		}
+	case syntax.OpRepeat:
+		fmt.Fprintf(b, " min=%d max=%d", re.Min, re.Max)
+	}
+	for _, f := range dumpFlags {
+		if re.Flags&f.flag != 0 {
+			b.WriteString(" " + f.name)
+		}
+	}
+	b.WriteByte('\n')
+	for _, sub := range re.Sub {
+		dumpNode(b, sub, depth+1)
+	}
+}
+
+// ExplainGeneration generates a string and renders how it came about, as
+// a teaching and debugging aid: the string, then the content of each
+// capture group that took part, keyed as GenerateWithCaptures keys them and
+// in the order the groups open, then the quantifier counts and alternation
+// branches chosen, in the order the walk made them. For
+// (?P<word>[a-z]+|[0-9]+);(x)? it might give
+//
+//	value: "ab;x"
+//	captures:
+//	  word: "ab"
+//	  2: "x"
+//	choices:
+//	  branch 0
+//	  repeat 2
+//	  repeat 1
+//
+// Rune picks are left out, being visible in the value. On failure the
+// text reports the error instead.
+func (x *Xeger) ExplainGeneration() string {
+	x.mu.Lock()
+	g := x.newGenerator(x.rng)
+	g.recording = true
+	g.captures = make(map[string]string)
+	s, err := g.generate()
+	x.mu.Unlock()
+	if err != nil {
+		return fmt.Sprintf("error: %v\n", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "value: %q\n", s)
+	if len(g.captures) > 0 {
+		b.WriteString("captures:\n")
+		for i, name := range x.regexp.SubexpNames()[1:] {
+			if name == "" {
+				name = strconv.Itoa(i + 1)
+			}
+			if v, ok := g.captures[name]; ok {
+				fmt.Fprintf(&b, "  %s: %q\n", name, v)
+			}
+		}
+	}
+	var choices []Decision
+	for _, d := range g.decisions {
+		if d.Kind != DecisionRune {
+			choices = append(choices, d)
+		}
+	}
+	if len(choices) > 0 {
+		b.WriteString("choices:\n")
+		for _, d := range choices {
+			fmt.Fprintf(&b, "  %s %d\n", d.Kind, d.Value)
+		}
+	}
+	return b.String()
+}
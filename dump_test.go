@@ -0,0 +1,78 @@
+package xeger
+
+import (
+	"regexp/syntax"
+	"strings"
+	"testing"
+)
+
+func TestOpName(t *testing.T) {
+	var tests = []struct {
+		Op   syntax.Op
+		Want string
+	}{
+		{syntax.OpNoMatch, "OpNoMatch"},
+		{syntax.OpLiteral, "OpLiteral"},
+		{syntax.OpEndLine, "OpEndLine"},
+		{syntax.OpEndText, "OpEndText"},
+		{syntax.OpWordBoundary, "OpWordBoundary"},
+		{syntax.OpCapture, "OpCapture"},
+		{syntax.OpRepeat, "OpRepeat"},
+		{syntax.OpAlternate, "OpAlternate"},
+		{0, "OpUnknown"},
+	}
+
+	for _, test := range tests {
+		if got := OpName(test.Op); got != test.Want {
+			t.Errorf("OpName(%d) = %s, want %s", test.Op, got, test.Want)
+		}
+	}
+}
+
+func TestDumpTree(t *testing.T) {
+	iRe, err := NewInverseRegex(`^(?P<id>[a-c0-9]{2,4})(?i:x)|y+?$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `OpAlternate
+  OpConcat
+    OpBeginText
+    OpCapture #1 "id"
+      OpRepeat min=2 max=4
+        OpCharClass ['0'-'9' 'a'-'c']
+    OpLiteral "X" FoldCase
+  OpConcat
+    OpPlus NonGreedy
+      OpLiteral "y"
+    OpEndText WasDollar
+`
+	if got := iRe.DumpTree(); got != want {
+		t.Errorf("got tree\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExplainGeneration(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<n>ab){2}(c)(?:d|ee)?`, WithSeed(1), WithQuestProbability(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `value: "ababc"
+captures:
+  n: "ab"
+  2: "c"
+choices:
+  repeat 2
+  repeat 0
+`
+	if got := iRe.ExplainGeneration(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	iRe, err = NewInverseRegex(`a[^\x00-\x{10FFFF}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := iRe.ExplainGeneration(); !strings.HasPrefix(got, "error: ") {
+		t.Errorf("got %q, want an error report", got)
+	}
+}
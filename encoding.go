@@ -0,0 +1,95 @@
+package xeger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// An Encoding is a character encoding generated output can be converted to
+// with GenerateEncoded. Encodings outside this package, such as Shift JIS,
+// can be supplied by implementing it over a transcoder of the caller's
+// choice.
+type Encoding interface {
+	// AppendRune appends the encoding of r to dst and returns the
+	// extended buffer, or reports false if r cannot be represented.
+	AppendRune(dst []byte, r rune) ([]byte, bool)
+}
+
+// Encodings provided with the package.
+var (
+	// ASCII encodes the runes up to U+007F as single bytes.
+	ASCII Encoding = singleByte(0x7f)
+	// Latin1 is ISO 8859-1, encoding the runes up to U+00FF as single
+	// bytes.
+	Latin1 Encoding = singleByte(0xff)
+	// UTF16LE is UTF-16 in little-endian byte order, without a byte
+	// order mark.
+	UTF16LE Encoding = utf16Encoding{binary.LittleEndian}
+	// UTF16BE is UTF-16 in big-endian byte order, without a byte order
+	// mark.
+	UTF16BE Encoding = utf16Encoding{binary.BigEndian}
+)
+
+// singleByte encodes each rune up to and including its value as the byte
+// of the same value.
+type singleByte rune
+
+func (top singleByte) AppendRune(dst []byte, r rune) ([]byte, bool) {
+	if r < 0 || r > rune(top) {
+		return dst, false
+	}
+	return append(dst, byte(r)), true
+}
+
+// utf16Encoding encodes runes as UTF-16 code units in byte order.
+type utf16Encoding struct {
+	order binary.AppendByteOrder
+}
+
+func (e utf16Encoding) AppendRune(dst []byte, r rune) ([]byte, bool) {
+	if !utf8.ValidRune(r) {
+		return dst, false
+	}
+	for _, u := range utf16.AppendRune(nil, r) {
+		dst = e.order.AppendUint16(dst, u)
+	}
+	return dst, true
+}
+
+// GenerateEncoded generates a string and returns it encoded with enc, for
+// consumers of encodings such as Latin-1 or UTF-16. Random picks are
+// restricted to runes enc can represent wherever the pattern allows a
+// choice; an error is returned if the pattern forces an unrepresentable
+// rune, such as a literal outside the encoding's repertoire.
+func (x *Xeger) GenerateEncoded(enc Encoding) ([]byte, error) {
+	representable := make(map[rune]bool)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	also := g.allow
+	g.allow = func(r rune) bool {
+		if also != nil && !also(r) {
+			return false
+		}
+		ok, seen := representable[r]
+		if !seen {
+			_, ok = enc.AppendRune(nil, r)
+			representable[r] = ok
+		}
+		return ok
+	}
+	s, err := g.generate()
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	for _, r := range s {
+		var ok bool
+		if b, ok = enc.AppendRune(b, r); !ok {
+			return nil, fmt.Errorf("xeger: encoding %q: %U is not representable", s, r)
+		}
+	}
+	return b, nil
+}
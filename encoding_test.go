@@ -0,0 +1,98 @@
+package xeger
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestGenerateEncoded(t *testing.T) {
+	iRe, err := NewInverseRegex(`[à-ÿ]{3}[^a]{5}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		b, err := iRe.GenerateEncoded(Latin1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 8 {
+			t.Fatalf("got %d bytes %q, want one byte per rune", len(b), b)
+		}
+		s := make([]rune, len(b))
+		for j, c := range b {
+			s[j] = rune(c)
+		}
+		if !iRe.regexp.MatchString(string(s)) {
+			t.Fatalf("decoded %q does not match", string(s))
+		}
+	}
+
+	iRe, err = NewInverseRegex(`[ぁ-ゖ]{4}😀`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range []struct {
+		enc Encoding
+		bo  binary.ByteOrder
+	}{{UTF16LE, binary.LittleEndian}, {UTF16BE, binary.BigEndian}} {
+		b, err := iRe.GenerateEncoded(order.enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 12 {
+			t.Fatalf("got %d bytes %q, want two per hiragana and four for the emoji", len(b), b)
+		}
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = order.bo.Uint16(b[2*i:])
+		}
+		if s := string(utf16.Decode(units)); !iRe.regexp.MatchString(s) {
+			t.Errorf("decoded %q does not match", s)
+		}
+	}
+}
+
+func TestGenerateEncodedRestrictsPicks(t *testing.T) {
+	iRe, err := NewInverseRegex(`.{20}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := iRe.GenerateEncoded(ASCII)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range b {
+		if c > 0x7f {
+			t.Fatalf("got %q, want only ASCII", b)
+		}
+	}
+}
+
+func TestGenerateEncodedUnrepresentable(t *testing.T) {
+	iRe, err := NewInverseRegex(`snow☃`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := iRe.GenerateEncoded(Latin1); err == nil {
+		t.Errorf("expected an error, got %q", b)
+	}
+}
+
+func TestGenerateEncodedDealtRunes(t *testing.T) {
+	for _, opt := range []Option{WithMaximizeVariety(true), WithDistinctAlternatesInRepeat(true)} {
+		iRe, err := NewInverseRegex(`[a-zà-ÿ]{30}`, WithSeed(1), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			b, err := iRe.GenerateEncoded(ASCII)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b) != 30 {
+				t.Fatalf("got %q, want 30 ASCII letters", b)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package xeger
+
+import (
+	"fmt"
+	"math/big"
+	"regexp/syntax"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxEnumeration is the most ways of generating a string Enumerate will
+// expand.
+const maxEnumeration = 1 << 20
+
+// Enumerate returns every string the finite pattern matches, in the order
+// the walk meets them: branches of an alternation left to right, and
+// repeats from fewest copies to most. A string producible in more than one
+// way, as from a|a, appears once for each. Strings failing a word boundary
+// are left out. It returns ErrTooManyMatches if the pattern is infinite or
+// CountMatches exceeds 1<<20. Checks and other options do not apply.
+func (x *Xeger) Enumerate() ([]string, error) {
+	n, ok := x.CountMatches()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is infinite", ErrTooManyMatches, x.re)
+	}
+	if n.Cmp(big.NewInt(maxEnumeration)) > 0 {
+		return nil, fmt.Errorf("%w: %s has %v, more than %d", ErrTooManyMatches, x.re, n, maxEnumeration)
+	}
+	all := expand(x.re)
+	out := all[:0]
+	for _, s := range all {
+		if x.regexp.MatchString(s) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// EnumerateSorted is like Enumerate, but returns the strings sorted by
+// bytes with duplicates removed, for comparing against golden files.
+func (x *Xeger) EnumerateSorted() ([]string, error) {
+	out, err := x.Enumerate()
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// expand returns the strings the finite pattern re can generate, in the
+// order the walk would meet them, one for each way of generating a string
+// as CountMatches counts them. Word boundaries are not checked, so some
+// results may not actually match.
+func expand(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return nil
+	case syntax.OpLiteral:
+		out := []string{""}
+		for _, r := range re.Rune {
+			cases := []rune{r}
+			if re.Flags&syntax.FoldCase != 0 {
+				for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+					cases = append(cases, f)
+				}
+			}
+			var next []string
+			for _, s := range out {
+				for _, c := range cases {
+					next = append(next, s+string(c))
+				}
+			}
+			out = next
+		}
+		return out
+	case syntax.OpCharClass:
+		return expandRanges(re.Rune)
+	case syntax.OpAnyChar:
+		return expandRanges(nonSurrogates)
+	case syntax.OpAnyCharNotNL:
+		return expandRanges(intersectRanges(nonSurrogates, negateRanges([]rune{'\n', '\n'})))
+	case syntax.OpCapture:
+		return expand(re.Sub[0])
+	case syntax.OpQuest:
+		return append([]string{""}, expand(re.Sub[0])...)
+	case syntax.OpRepeat:
+		if zeroWidth(re) {
+			return []string{""}
+		}
+		sub := expand(re.Sub[0])
+		var out []string
+		reps := []string{""}
+		for i := 0; i <= re.Max; i++ {
+			if i >= re.Min {
+				out = append(out, reps...)
+			}
+			if i < re.Max {
+				reps = product(reps, sub)
+			}
+		}
+		return out
+	case syntax.OpConcat:
+		out := []string{""}
+		for _, sub := range re.Sub {
+			out = product(out, expand(sub))
+		}
+		return out
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			out = append(out, expand(sub)...)
+		}
+		return out
+	}
+	// empty matches, zero-width assertions and repeats of them
+	return []string{""}
+}
+
+// expandRanges returns one string for each rune in ranges, a list of
+// inclusive lo, hi pairs.
+func expandRanges(ranges []rune) []string {
+	var out []string
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if utf8.ValidRune(r) {
+				out = append(out, string(r))
+			}
+		}
+	}
+	return out
+}
+
+// product returns every concatenation of a string from a with one from b.
+func product(a, b []string) []string {
+	out := make([]string, 0, len(a)*len(b))
+	for _, s := range a {
+		for _, t := range b {
+			out = append(out, s+t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package xeger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+		Sorted  string
+	}{
+		{`[ba][10]`, "a0 a1 b0 b1", "a0 a1 b0 b1"},
+		{`zz|y`, "zz y", "y zz"},
+		{`b(?:cd|a)?`, "b bcd ba", "b ba bcd"},
+		{`(?:a|ab)b?`, "a ab ab abb", "a ab abb"},
+		{`(?:ab|a){2}`, "abab aba aab aa", "aa aab aba abab"},
+		{`\ba\b|a\bb`, "a", "a"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got, err := iRe.Enumerate()
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if strings.Join(got, " ") != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+		sorted, err := iRe.EnumerateSorted()
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if strings.Join(sorted, " ") != test.Sorted {
+			t.Errorf("%s: sorted %q, want %q", test.Pattern, sorted, test.Sorted)
+		}
+	}
+}
+
+func TestEnumerateTooMany(t *testing.T) {
+	for _, pattern := range []string{`a+`, `[a-z]{5}`} {
+		iRe, err := NewInverseRegex(pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		if _, err := iRe.EnumerateSorted(); !errors.Is(err, ErrTooManyMatches) {
+			t.Errorf("%s: got error %v, want ErrTooManyMatches", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package xeger
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// Errors returned by generation, usually wrapped with details of the
+// failure. Test for them with errors.Is.
+var (
+	// ErrRetryExhausted means no string satisfying the configured
+	// constraints was found within the retry limit.
+	ErrRetryExhausted = errors.New("xeger: retries exhausted")
+
+	// ErrLengthInfeasible means a requested length can never be produced
+	// by the pattern.
+	ErrLengthInfeasible = errors.New("xeger: length infeasible")
+
+	// ErrUnsupportedOp means the pattern uses an operation the generator
+	// cannot produce text for.
+	ErrUnsupportedOp = errors.New("xeger: unsupported op")
+
+	// ErrDepthExceeded means the pattern nests deeper than the configured
+	// maximum depth.
+	ErrDepthExceeded = errors.New("xeger: max depth exceeded")
+
+	// ErrNoMatch means part of the pattern, such as an empty char class,
+	// matches no string at all.
+	ErrNoMatch = errors.New("xeger: pattern matches nothing")
+
+	// ErrBadDecisions means a decision log passed for replay does not fit
+	// the pattern.
+	ErrBadDecisions = errors.New("xeger: decisions do not fit the pattern")
+
+	// ErrPossessive means the pattern uses a possessive quantifier such
+	// as a{2,4}+ or a*+, which RE2 does not support.
+	ErrPossessive = errors.New("xeger: possessive quantifiers are not supported")
+
+	// ErrMismatch means a generated string failed to match the pattern.
+	ErrMismatch = errors.New("xeger: generated string does not match")
+
+	// ErrUnprintable means the pattern forces an unprintable rune under
+	// WithPrintableStrict.
+	ErrUnprintable = errors.New("xeger: pattern forces unprintable runes")
+
+	// ErrTooManyMatches means the pattern matches too many strings, or
+	// infinitely many, to enumerate them all.
+	ErrTooManyMatches = errors.New("xeger: too many matches to enumerate")
+)
+
+// explainParseError returns err, a failure to compile pattern, made
+// clearer where possible. RE2 reports a possessive quantifier as a nested
+// repetition, which hides that the pattern is written for another engine.
+func explainParseError(pattern string, err error) error {
+	var serr *syntax.Error
+	if !errors.As(err, &serr) || serr.Code != syntax.ErrInvalidRepeatOp || len(serr.Expr) < 2 || !strings.HasSuffix(serr.Expr, "+") {
+		return err
+	}
+	q := serr.Expr[:len(serr.Expr)-1]
+	if q != "*" && q != "+" && q != "?" && (q[0] != '{' || strings.Count(q, "{") != 1) {
+		// stacked quantifiers such as a{2}{3}+, not a possessive one
+		return err
+	}
+	return fmt.Errorf("%w: %s in %q; use %s for a greedy repeat: %w", ErrPossessive, serr.Expr, pattern, q, err)
+}
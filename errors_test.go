@@ -0,0 +1,61 @@
+package xeger
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+)
+
+func TestErrorsIs(t *testing.T) {
+	iRe, err := NewInverseRegex(`abc|abcdef`, WithLengthRange(4, 5), WithMaxRetries(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.Generate(); !errors.Is(err, ErrRetryExhausted) {
+		t.Errorf("got %v, want ErrRetryExhausted", err)
+	}
+
+	if _, err := NewInverseRegex(`abc`, WithLengthRange(5, 6)); !errors.Is(err, ErrLengthInfeasible) {
+		t.Errorf("got %v, want ErrLengthInfeasible", err)
+	}
+
+	iRe, err = NewInverseRegex(`[^\x00-\x{10FFFF}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.Generate(); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got %v, want ErrNoMatch", err)
+	}
+
+	iRe, err = NewInverseRegex(`x`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iRe.re.Op = 0
+	if _, err := iRe.Generate(); !errors.Is(err, ErrUnsupportedOp) {
+		t.Errorf("got %v, want ErrUnsupportedOp", err)
+	}
+}
+
+func TestPossessiveQuantifier(t *testing.T) {
+	for _, pattern := range []string{`a{2,4}+`, `a++b`, `x*+`, `(ab)?+`, `[0-9]{3}+-`} {
+		_, err := NewInverseRegex(pattern)
+		if !errors.Is(err, ErrPossessive) {
+			t.Errorf("%s: got %v, want ErrPossessive", pattern, err)
+			continue
+		}
+		var serr *syntax.Error
+		if !errors.As(err, &serr) {
+			t.Errorf("%s: %v does not wrap the parse error", pattern, err)
+		}
+	}
+	for _, pattern := range []string{`a**`, `a{2}{3}`, `a{2}{3}+`} {
+		if _, err := NewInverseRegex(pattern); err == nil || errors.Is(err, ErrPossessive) {
+			t.Errorf("%s: got %v, want a plain parse error", pattern, err)
+		}
+	}
+	// lazy quantifiers are fine
+	if _, err := NewInverseRegex(`a+?b{2,4}?`); err != nil {
+		t.Error(err)
+	}
+}
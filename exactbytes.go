@@ -0,0 +1,235 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// WithLengthDistribution steers each generated string towards a length in
+// runes drawn from sample, such as one returning mostly 8 to 12 and
+// occasionally 20, so that a corpus follows a realistic distribution of
+// lengths. Repeat counts and branches are steered as for
+// GenerateExactBytes, but the target is only approached, not required: a
+// target the pattern cannot reach is replaced by the nearest length it
+// can. Attempts that miss the target are retried, and if all miss the
+// last is returned. sample is called once per string, and must be safe
+// for concurrent use if x is.
+func WithLengthDistribution(sample func() int) Option {
+	return func(x *Xeger) error {
+		if sample == nil {
+			return fmt.Errorf("xeger: nil length distribution")
+		}
+		x.lengthSample = sample
+		return nil
+	}
+}
+
+// GenerateExactBytes generates a string whose UTF-8 encoding is exactly n
+// bytes long, for fixed-width record formats. Repeat counts and branches
+// are steered towards the target using the byte length bounds of what is
+// left to generate. Attempts take turns at picking char class runes
+// freely, restricting them to single-byte runes and restricting them to
+// multi-byte runes, wherever the class has any of the kind, so that
+// targets needing narrow or wide text are both reached. Attempts are
+// retried up to the configured limit, after which ErrRetryExhausted is
+// returned. ErrLengthInfeasible is returned up front if the pattern can
+// never produce n bytes. Surrounding noise is not steered, so combined
+// with WithSurroundingNoise the target is rarely hit.
+func (x *Xeger) GenerateExactBytes(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("xeger: invalid byte length %d", n)
+	}
+	if lo, hi := byteBounds(x.re); n < lo || (hi != -1 && n > hi) {
+		return "", fmt.Errorf("%w: %d bytes against pattern byte lengths [%d, %d]", ErrLengthInfeasible, n, lo, hi)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	s := &steering{target: n, inBytes: true, bounds: make(map[*syntax.Regexp][2]int)}
+	g.steer = s
+	also := g.allow
+	g.allow = func(r rune) bool {
+		if also != nil && !also(r) {
+			return false
+		}
+		switch s.attempts % 3 {
+		case 1:
+			return r < utf8.RuneSelf
+		case 2:
+			return r >= utf8.RuneSelf
+		}
+		return true
+	}
+	g.check = func(out string) bool { return len(out) == n }
+	return g.generate()
+}
+
+// steering tracks what a walk aiming for a target length, in bytes if
+// inBytes is set and otherwise in runes, has left to generate.
+type steering struct {
+	target  int
+	inBytes bool
+	start   int
+
+	// capped makes target a budget not to exceed rather than a length
+	// to hit.
+	capped bool
+
+	// sample, when set, draws a new target for each string, which is
+	// clamped to [lo, hi], with a hi of -1 meaning unbounded.
+	sample func() int
+	lo, hi int
+
+	// tailMin and tailMax bound the bytes the walk still has to generate
+	// after the node being generated, with a max of -1 meaning unbounded.
+	tailMin, tailMax int
+
+	attempts int
+	bounds   map[*syntax.Regexp][2]int
+}
+
+// reset prepares for a new attempt starting at offset start of the buffer.
+func (s *steering) reset(start int) {
+	s.start = start
+	s.tailMin, s.tailMax = 0, 0
+	s.attempts++
+}
+
+// draw sets the target to a fresh sample, clamped to the reachable
+// lengths.
+func (s *steering) draw() {
+	s.target = max(s.sample(), s.lo)
+	if s.hi != -1 {
+		s.target = min(s.target, s.hi)
+	}
+}
+
+// hit reports whether out, a generated string, has the target length.
+func (s *steering) hit(out []byte) bool {
+	if s.inBytes {
+		return len(out) == s.target
+	}
+	return utf8.RuneCount(out) == s.target
+}
+
+// measure returns the length bounds of re, remembering them.
+func (s *steering) measure(re *syntax.Regexp) (min, max int) {
+	b, ok := s.bounds[re]
+	if !ok {
+		b[0], b[1] = measure(re, s.inBytes)
+		s.bounds[re] = b
+	}
+	return b[0], b[1]
+}
+
+// remaining returns how much the walk still needs to hit the target.
+func (g *generator) remaining() int {
+	done := g.buf[g.steer.start:]
+	if g.steer.inBytes {
+		return g.steer.target - len(done)
+	}
+	return g.steer.target - utf8.RuneCount(done)
+}
+
+// steerConcat generates each part of the concatenation re, telling each
+// how much is still to come after it.
+func (g *generator) steerConcat(re *syntax.Regexp) error {
+	s := g.steer
+	outerMin, outerMax := s.tailMin, s.tailMax
+	defer func() { s.tailMin, s.tailMax = outerMin, outerMax }()
+
+	after := make([][2]int, len(re.Sub)+1)
+	after[len(re.Sub)] = [2]int{outerMin, outerMax}
+	for i := len(re.Sub) - 1; i >= 0; i-- {
+		lo, hi := s.measure(re.Sub[i])
+		after[i] = [2]int{saturate(addLen(after[i+1][0], lo)), addLen(after[i+1][1], hi)}
+	}
+	for i, sub := range re.Sub {
+		s.tailMin, s.tailMax = after[i+1][0], after[i+1][1]
+		if err := g.makeMatch(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// steerRepeat generates count copies of sub, telling each how much is
+// still to come after it.
+func (g *generator) steerRepeat(sub *syntax.Regexp, count int) error {
+	s := g.steer
+	outerMin, outerMax := s.tailMin, s.tailMax
+	defer func() { s.tailMin, s.tailMax = outerMin, outerMax }()
+
+	lo, hi := s.measure(sub)
+	for i := 0; i < count; i++ {
+		left := count - 1 - i
+		s.tailMin = saturate(addLen(outerMin, mulLen(lo, left)))
+		s.tailMax = addLen(outerMax, mulLen(hi, left))
+		if err := g.makeMatch(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// steerCount picks a count in [min, max] (max -1 unbounded) for repeating
+// the sub of the quantifier re that can still reach the target, or within
+// a budget can still fit it, or returns false if none can.
+func (g *generator) steerCount(re *syntax.Regexp, min, max int) (int, bool) {
+	s := g.steer
+	rem := g.remaining()
+	sub := re.Sub[0]
+	subMin, subMax := s.measure(sub)
+
+	// Copies must leave room for the minimum of what follows, and with
+	// bounded copies and tail, reach the rest of the target.
+	hi := max
+	if subMin > 0 {
+		if n := (rem - s.tailMin) / subMin; hi == -1 || n < hi {
+			hi = n
+		}
+	} else if hi == -1 {
+		hi = min + g.maxReps
+	}
+	if s.capped {
+		if hi < min {
+			return 0, false
+		}
+		n := g.drawCount(re, min, max)
+		if n > hi {
+			n = min + g.rng.Intn(hi-min+1)
+		}
+		return n, true
+	}
+	lo := min
+	if subMax > 0 && s.tailMax != -1 {
+		if n := (rem - s.tailMax + subMax - 1) / subMax; n > lo {
+			lo = n
+		}
+	}
+	if lo > hi {
+		return 0, false
+	}
+	return lo + g.rng.Intn(hi-lo+1), true
+}
+
+// steerBranch picks a branch of the alternation re that can still reach
+// the target, or within a budget can still fit it, or returns false if
+// none can.
+func (g *generator) steerBranch(re *syntax.Regexp) (int, bool) {
+	s := g.steer
+	rem := g.remaining()
+	var fits []int
+	for i, sub := range re.Sub {
+		lo, hi := s.measure(sub)
+		if lo+s.tailMin <= rem && (s.capped || hi == -1 || s.tailMax == -1 || hi+s.tailMax >= rem) {
+			fits = append(fits, i)
+		}
+	}
+	if len(fits) == 0 {
+		return 0, false
+	}
+	return fits[g.rng.Intn(len(fits))], true
+}
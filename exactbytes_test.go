@@ -0,0 +1,168 @@
+package xeger
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestGenerateExactBytes(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Lengths []int
+	}{
+		{`[a-z]+`, []int{1, 5, 50, 500}},
+		{`[a-z]{3,}-[0-9]+`, []int{5, 20, 200}},
+		{`(foo|quux|x)+`, []int{1, 4, 11, 100}},
+		{`[aé]{2}`, []int{2, 3, 4}},
+		{`(?:日本|[a-z]{2,5})*\.txt`, []int{4, 10, 37}},
+		{`é+`, []int{2, 40}},
+		{`[a-zé]{3,9}`, []int{3, 12, 18}},
+		{`a?b?c?`, []int{0, 1, 2, 3}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for _, n := range test.Lengths {
+			for i := 0; i < 10; i++ {
+				s, err := iRe.GenerateExactBytes(n)
+				if err != nil {
+					t.Fatalf("%s: %d bytes: %v", test.Pattern, n, err)
+				}
+				if len(s) != n || !iRe.regexp.MatchString(s) {
+					t.Fatalf("%s: got %q for %d bytes", test.Pattern, s, n)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateExactBytesKeepsRestrictions(t *testing.T) {
+	iRe, err := NewInverseRegex(`[\x00-\x7f]{10}`, WithSeed(1), WithPrintable(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateExactBytes(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }) != -1 {
+			t.Fatalf("%q is not printable", s)
+		}
+	}
+}
+
+func TestGenerateExactBytesImpossible(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		N       int
+		Want    error
+	}{
+		{`[a-z]{2,4}`, 9, ErrLengthInfeasible},
+		{`[a-z]{2,4}`, 1, ErrLengthInfeasible},
+		{`é{2}`, 3, ErrLengthInfeasible},
+		{`é+`, 5, ErrRetryExhausted},
+		{`.{0,5}é`, 12, ErrLengthInfeasible},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if s, err := iRe.GenerateExactBytes(test.N); !errors.Is(err, test.Want) {
+			t.Errorf("%s: %d bytes: got %q, %v, want %v", test.Pattern, test.N, s, err, test.Want)
+		}
+	}
+}
+
+func TestByteBounds(t *testing.T) {
+	var tests = []struct {
+		Pattern  string
+		Min, Max int
+	}{
+		{`abc`, 3, 3},
+		{`é€`, 5, 5},
+		{`[a-é]`, 1, 2},
+		{`(?i)k`, 1, 3},
+		{`.`, 1, 1},
+		{`(日|a){2,3}`, 2, 9},
+		{`é*`, 0, -1},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if min, max := byteBounds(iRe.re); min != test.Min || max != test.Max {
+			t.Errorf("%s: got byte lengths [%d, %d], want [%d, %d]", test.Pattern, min, max, test.Min, test.Max)
+		}
+	}
+}
+
+func TestWithLengthDistribution(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Target  int
+		Want    int
+	}{
+		{`[a-z]{1,30}`, 10, 10},
+		{`[a-z]+(?:-[0-9]{2,4})?`, 7, 7},
+		{`(?:ab|cde)+`, 12, 12},
+		{`é+`, 4, 4},
+		// unreachable targets get the nearest length
+		{`[a-z]{3,5}`, 20, 5},
+		{`[a-z]{3,5}`, -1, 3},
+	}
+
+	for _, test := range tests {
+		target := test.Target
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithLengthDistribution(func() int { return target }))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 20; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if n := utf8.RuneCountInString(s); n != test.Want {
+				t.Fatalf("%s: %q has %d runes, want %d", test.Pattern, s, n, test.Want)
+			}
+		}
+	}
+}
+
+func TestWithLengthDistributionSpread(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	sample := func() int {
+		if rng.Intn(10) == 0 {
+			return 20
+		}
+		return 8 + rng.Intn(5)
+	}
+	iRe, err := NewInverseRegex(`[a-z]+`, WithSeed(1), WithMaxReps(30), WithLengthDistribution(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[int]int)
+	for i := 0; i < 500; i++ {
+		s, err := iRe.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[len(s)]++
+	}
+	normal := counts[8] + counts[9] + counts[10] + counts[11] + counts[12]
+	if normal+counts[20] != 500 || counts[20] < 20 || counts[20] > 90 {
+		t.Errorf("got lengths %v", counts)
+	}
+}
@@ -0,0 +1,72 @@
+package xeger
+
+import "unicode"
+
+// A FoldStrategy chooses how case-insensitive literals, such as the ABC in
+// (?i)ABC, are cased in generated output. Literals outside the scope of the
+// flag are always written exactly as in the pattern. The parser does not
+// record how a case-insensitive literal was written, so no strategy can
+// reproduce its original case.
+type FoldStrategy int
+
+const (
+	// FoldLower writes the lowercase form of each letter, giving abc for
+	// (?i)ABC. It is the default.
+	FoldLower FoldStrategy = iota
+	// FoldUpper writes the uppercase form of each letter.
+	FoldUpper
+	// FoldRandom picks any member of each letter's case folding orbit,
+	// such as the Kelvin sign for (?i)k.
+	FoldRandom
+)
+
+// WithFoldStrategy sets how case-insensitive literals are cased. The
+// default, FoldLower, gives readable canonical output; FoldRandom exercises
+// case-insensitive matching in the consumer.
+func WithFoldStrategy(s FoldStrategy) Option {
+	return func(x *Xeger) error {
+		x.foldStrategy = s
+		return nil
+	}
+}
+
+// foldCase returns the case of r that the fold strategy calls for. A case
+// outside r's folding orbit, such as the i unicode.ToLower gives for İ, or
+// one the generator does not allow, is replaced by a random member of the
+// orbit.
+func (g *generator) foldCase(r rune) rune {
+	var c rune
+	switch g.x.foldStrategy {
+	case FoldLower:
+		c = unicode.ToLower(r)
+	case FoldUpper:
+		c = unicode.ToUpper(r)
+	default:
+		return g.foldRune(r)
+	}
+	if !foldsTo(r, c) || (g.allow != nil && !g.allow(c)) {
+		return g.foldRune(r)
+	}
+	return c
+}
+
+// foldsTo reports whether c is in the case folding orbit of r.
+func foldsTo(r, c rune) bool {
+	for f := unicode.SimpleFold(r); ; f = unicode.SimpleFold(f) {
+		if f == c {
+			return true
+		}
+		if f == r {
+			return false
+		}
+	}
+}
+
+// orbitSize returns the number of runes in the case folding orbit of r.
+func orbitSize(r rune) int {
+	n := 1
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package xeger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldStrategy(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+		Want    string
+	}{
+		{`(?i)ABC`, nil, "abc"},
+		{`(?i)AbC-12`, nil, "abc-12"},
+		{`(?i)ΣΣ`, nil, "σσ"},
+		{`(?i)\x{212a}`, nil, "k"},
+		{`(?i)abc`, []Option{WithFoldStrategy(FoldLower)}, "abc"},
+		{`(?i)abc`, []Option{WithFoldStrategy(FoldUpper)}, "ABC"},
+		{`x(?i:y)Z`, []Option{WithFoldStrategy(FoldUpper)}, "xYZ"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, test.Opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 10; i++ {
+			got, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if got != test.Want {
+				t.Fatalf("%s: got %q, want %q", test.Pattern, got, test.Want)
+			}
+		}
+	}
+}
+
+func TestLiteralsVerbatim(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+		Prefix  string
+	}{
+		{`user_[0-9]{3}`, nil, "user_"},
+		{`User_[0-9]{3}`, []Option{WithFoldStrategy(FoldRandom)}, "User_"},
+		{`ÜBER-ß[a-z]`, nil, "ÜBER-ß"},
+		{`日本\.[a-z]+`, nil, "日本."},
+		{`\x00\x{10FFFF}[0-9]`, nil, "\x00\U0010ffff"},
+		{`Ab(?i:cD)Ef[0-9]`, nil, "AbcdEf"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if !strings.HasPrefix(s, test.Prefix) {
+				t.Fatalf("%s: got %q, want prefix %q", test.Pattern, s, test.Prefix)
+			}
+		}
+	}
+}
+
+func TestFoldStrategyStaysInOrbit(t *testing.T) {
+	// unicode.ToLower and ToUpper map these outside their folding orbits
+	for _, pattern := range []string{`(?i)İ`, `(?i)ı`, `(?i)K`, `(?i)\x{212A}`} {
+		for _, s := range []FoldStrategy{FoldLower, FoldUpper} {
+			iRe, err := NewInverseRegex(pattern, WithSeed(1), WithFoldStrategy(s))
+			if err != nil {
+				t.Fatalf("%s: unexpected error %v", pattern, err)
+			}
+			for i := 0; i < 10; i++ {
+				got, err := iRe.GenerateValid()
+				if err != nil {
+					t.Fatalf("%s with strategy %d: %v", pattern, s, err)
+				}
+				if got == "i" || got == "I" {
+					t.Fatalf("%s with strategy %d: got %q", pattern, s, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package xeger
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+)
+
+// FuzzGenerate checks the core contract: for any pattern that parses, every
+// generated string is matched by the pattern. Patterns no string satisfies,
+// such as 0$0, are skipped. Run it with
+// go test -fuzz=FuzzGenerate; without -fuzz only the seed corpus is used.
+func FuzzGenerate(f *testing.F) {
+	for _, pattern := range []string{
+		``,
+		`^$`,
+		`abc`,
+		`foo.*`,
+		`a(x*)b(y|z)c`,
+		`[a-z][\.\?!]\s+[A-Z]`,
+		`^[0-9a-z]+\[[0-9]{3,5}\]$`,
+		`(?i)straße`,
+		`[^aeiou]{2,}`,
+		`(a|b|)+c?`,
+		`0$00(00)0(000)0`,
+	} {
+		f.Add(pattern, int64(1))
+	}
+
+	f.Fuzz(func(t *testing.T, pattern string, seed int64) {
+		iRe, err := NewInverseRegex(pattern, WithSeed(seed))
+		if err != nil {
+			t.Skip()
+		}
+		if iRe.Analyze().PotentiallyExplosive {
+			t.Skip()
+		}
+		generate := iRe.GenerateValid
+		if hasAssertion(iRe.re) {
+			// Generation does not honour anchors and word boundaries,
+			// so re-roll until they hold.
+			generate = func() (string, error) {
+				return iRe.GenerateSatisfying(func(string) bool { return true })
+			}
+		}
+		switch _, err := generate(); {
+		case errors.Is(err, ErrNoMatch), errors.Is(err, ErrRetryExhausted):
+			t.Skip()
+		case err != nil:
+			t.Fatal(err)
+		}
+	})
+}
+
+// hasAssertion reports whether re contains an anchor or word boundary.
+func hasAssertion(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasAssertion(sub) {
+			return true
+		}
+	}
+	return false
+}
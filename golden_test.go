@@ -0,0 +1,36 @@
+package xeger
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestGolden pins the output of fixed seeds. A failure here means seeded
+// output has changed, which breaks users' golden files: only update the
+// expectations for a deliberate change to the order of random draws
+// described in the package documentation.
+func TestGolden(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    []string
+	}{
+		{`[a-z]{3,8}@(?:example|test)\.(?:com|org)`, []string{"xelvznqa@test.org", "afsyhohj@example.com", "rijbcwf@example.com"}},
+		{`(?i)id-[0-9a-f]{4}(?:-x)?`, []string{"id-9D47-x", "id-Ffee", "id-eF0f"}},
+		{`(ab|cd)*[^a-z]+\w{2}`, []string{"\U00059ae6Tt", "\U00056106Zk", "\U000ae846AN"}},
+		{`.{4}|\d{3}`, []string{"109", "178", "3[X4"}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(42))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got, err := iRe.GenerateN(len(test.Want))
+		if err != nil {
+			t.Fatalf("%s: %v", test.Pattern, err)
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("%s: seed 42 gave %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package xeger
+
+// maxMinimizeReplays bounds the number of candidate logs Minimize tries.
+const maxMinimizeReplays = 10000
+
+// Minimize shrinks a decision log, such as one from GenerateWithDecisions,
+// whose string makes stillFails report true, returning a smaller log and
+// its string that still do. It repeatedly tries truncating the log, so
+// that the remaining decisions take their smallest choices, lowering
+// individual decisions (fewer repetitions, earlier branches, smaller
+// runes) and dropping runs of decisions along with one repetition of an
+// earlier quantifier, keeping any change that yields a shorter string, or
+// one as short but built from smaller decisions, that still fails. Every
+// candidate is replayed through the pattern, so the result always matches
+// it. The search stops when no change helps or after maxMinimizeReplays
+// candidates. If decisions cannot be replayed or do not fail to begin
+// with, they are returned unchanged with the string they produce, if any.
+func (x *Xeger) Minimize(decisions []Decision, stillFails func(string) bool) ([]Decision, string) {
+	s, cur, err := x.replay(decisions)
+	if err != nil {
+		return decisions, ""
+	}
+	if !stillFails(s) {
+		return decisions, s
+	}
+
+	m := &minimizer{x: x, stillFails: stillFails, decisions: cur, s: s}
+	for m.shrink() {
+	}
+	return m.decisions, m.s
+}
+
+// minimizer holds the smallest failing log found so far.
+type minimizer struct {
+	x          *Xeger
+	stillFails func(string) bool
+	decisions  []Decision
+	s          string
+	replays    int
+}
+
+// shrink makes one improvement to the log, reporting whether it found one.
+func (m *minimizer) shrink() bool {
+	cur := m.decisions
+	for k := 0; k < len(cur); k++ {
+		if m.try(cur[:k]) {
+			return true
+		}
+	}
+	for i, d := range cur {
+		for _, v := range lowerValues(d) {
+			c := append([]Decision(nil), cur...)
+			c[i].Value = v
+			if m.try(c) {
+				return true
+			}
+		}
+	}
+	for j, d := range cur {
+		if d.Kind != DecisionRepeat || d.Value == 0 {
+			continue
+		}
+		for i := j + 1; i < len(cur); i++ {
+			for w := 1; w <= 4 && i+w <= len(cur); w++ {
+				c := append(append([]Decision(nil), cur[:i]...), cur[i+w:]...)
+				c[j].Value--
+				if m.try(c) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// try replays c and keeps it if it still fails and is an improvement.
+func (m *minimizer) try(c []Decision) bool {
+	if m.replays == maxMinimizeReplays {
+		return false
+	}
+	m.replays++
+	s, full, err := m.x.replay(c)
+	if err != nil || !m.stillFails(s) {
+		return false
+	}
+	if len(s) > len(m.s) || len(s) == len(m.s) && !lessDecisions(full, m.decisions) {
+		return false
+	}
+	m.decisions, m.s = full, s
+	return true
+}
+
+// lowerValues returns values to try in place of d's, smallest first. Runes
+// are tried as the first digit and letters, which start most classes,
+// before their predecessor.
+func lowerValues(d Decision) []int {
+	var candidates []int
+	if d.Kind == DecisionRune {
+		candidates = []int{'0', 'A', 'a', d.Value - 1}
+	} else {
+		candidates = []int{0, d.Value / 2, d.Value - 1}
+	}
+	var vs []int
+	for _, v := range candidates {
+		if v >= 0 && v < d.Value && (len(vs) == 0 || v > vs[len(vs)-1]) {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// lessDecisions reports whether a sorts before b, comparing values in
+// order with a proper prefix sorting first.
+func lessDecisions(a, b []Decision) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Value != b[i].Value {
+			return a[i].Value < b[i].Value
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,55 @@
+package xeger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMinimize(t *testing.T) {
+	var tests = []struct {
+		Pattern    string
+		StillFails func(string) bool
+		Want       string
+	}{
+		{`[a-z]{1,20}-[0-9]{1,5}`, func(s string) bool { return strings.Contains(s, "q") }, "q-0"},
+		{`(alpha|be|c)+x`, func(s string) bool { return strings.Contains(s, "be") }, "bex"},
+		{`[a-z]{3,10}`, func(s string) bool { return len(s) > 4 }, "aaaaa"},
+		{`[0-9]{2}:[0-9]{2}`, func(s string) bool { return s[0] > '3' }, "40:00"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for found := 0; found < 3; {
+			s, decisions := iRe.GenerateWithDecisions()
+			if !test.StillFails(s) {
+				continue
+			}
+			found++
+			shrunk, got := iRe.Minimize(decisions, test.StillFails)
+			if got != test.Want {
+				t.Errorf("%s: minimizing %q gave %q, want %q", test.Pattern, s, got, test.Want)
+			}
+			if !iRe.regexp.MatchString(got) || !test.StillFails(got) {
+				t.Errorf("%s: minimized %q no longer matches or fails", test.Pattern, got)
+			}
+			if replayed, err := iRe.ReplayDecisions(shrunk); err != nil || replayed != got {
+				t.Errorf("%s: replaying minimized decisions gave %q, %v, want %q", test.Pattern, replayed, err, got)
+			}
+		}
+	}
+}
+
+func TestMinimizePassing(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{5}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, decisions := iRe.GenerateWithDecisions()
+	shrunk, got := iRe.Minimize(decisions, func(string) bool { return false })
+	if got != s || len(shrunk) != len(decisions) {
+		t.Errorf("got %q from %d decisions, want the input %q unchanged", got, len(shrunk), s)
+	}
+}
@@ -0,0 +1,74 @@
+package xeger
+
+import (
+	"fmt"
+	"math/rand"
+	"unicode/utf8"
+)
+
+// nearMissRunes are tried, in order, when changing or inserting a rune to
+// break an otherwise valid match.
+var nearMissRunes = []rune{'a', 'Z', '0', ' ', '-', '_', '.', '~', '\n', '\x00'}
+
+// GenerateNearMiss returns a string that is a single edit away from a valid
+// match but is itself rejected by the regular expression. The edit is the
+// first of dropping a rune, changing a rune, or inserting a rune that makes
+// the result fail to match, with the position of each kind of edit tried in
+// random order. Under WithSurroundingNoise the result contains no match at
+// all.
+func (x *Xeger) GenerateNearMiss() (string, error) {
+	s, err := x.GenerateValid()
+	if err != nil {
+		return "", err
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if miss, ok := nearMiss(s, x.rng, func(c string) bool { return !x.matches(c) }); ok {
+		return miss, nil
+	}
+	return "", fmt.Errorf("%w: no single edit of %q fails to match %s", ErrRetryExhausted, s, x.re)
+}
+
+// nearMiss tries the single-rune edits of s, cheapest kind first and at
+// positions shuffled by rng, returning the first that rejected reports
+// true for. Each candidate is built only when it is tried.
+func nearMiss(s string, rng *rand.Rand, rejected func(string) bool) (string, bool) {
+	var starts []int
+	for i := range s {
+		starts = append(starts, i)
+	}
+	shuffled := func(positions []int) []int {
+		p := append([]int(nil), positions...)
+		rng.Shuffle(len(p), func(i, j int) { p[i], p[j] = p[j], p[i] })
+		return p
+	}
+
+	// drop a rune
+	for _, i := range shuffled(starts) {
+		_, n := utf8.DecodeRuneInString(s[i:])
+		if c := s[:i] + s[i+n:]; rejected(c) {
+			return c, true
+		}
+	}
+	// change a rune, trying its neighbours before the fixed candidates
+	for _, i := range shuffled(starts) {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		for _, c := range append([]rune{r + 1, r - 1}, nearMissRunes...) {
+			if c == r || !utf8.ValidRune(c) {
+				continue
+			}
+			if edit := s[:i] + string(c) + s[i+n:]; rejected(edit) {
+				return edit, true
+			}
+		}
+	}
+	// insert a rune
+	for _, i := range shuffled(append(starts, len(s))) {
+		for _, c := range nearMissRunes {
+			if edit := s[:i] + string(c) + s[i:]; rejected(edit) {
+				return edit, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package xeger
+
+import "testing"
+
+func TestGenerateNearMiss(t *testing.T) {
+	var tests = []string{
+		``,
+		`^$`,
+		`a`,
+		`abc`,
+		`^foo$`,
+		`héllo`,
+	}
+
+	for _, pattern := range tests {
+		iRe, err := NewInverseRegex(pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		miss, err := iRe.GenerateNearMiss()
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", pattern, err)
+			continue
+		}
+		if iRe.regexp.MatchString(miss) {
+			t.Errorf("%s: near miss %q unexpectedly matches", pattern, miss)
+		}
+	}
+}
+
+func TestGenerateNearMissMatchesEverything(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?s:.)*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.GenerateNearMiss(); err == nil {
+		t.Errorf("expected an error for a pattern matching every string")
+	}
+}
+
+func TestGenerateNearMissNoise(t *testing.T) {
+	iRe, err := NewInverseRegex(`[0-9]{3}`, WithSeed(1), WithSurroundingNoise(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		miss, err := iRe.GenerateNearMiss()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if iRe.search.MatchString(miss) {
+			t.Fatalf("near miss %q still contains a match", miss)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package xeger
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// noiseChars are the characters surrounding noise is drawn from.
+const noiseChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 .,;:-_"
+
+// maxNoiseLen is the most noise added to either side of a match.
+const maxNoiseLen = 8
+
+// WithSurroundingNoise surrounds each generated match with random text, so
+// the result contains a match rather than being one. This is for testing
+// searches instead of whole-string validation. Results are re-rolled
+// until the pattern matches exactly one non-empty span, so the noise never
+// introduces extra matches. No noise is added before a pattern anchored
+// with \A or ^, nor after one anchored with \z or $. Under (?m), where ^
+// and $ match at line breaks, the noise is instead separated from the
+// match by a newline.
+func WithSurroundingNoise(enabled bool) Option {
+	return func(x *Xeger) error {
+		if enabled && !x.noise {
+			x.checks = append(x.checks, x.singleMatch)
+		}
+		x.noise = enabled
+		return nil
+	}
+}
+
+// singleMatch reports whether s contains exactly one non-empty match, or
+// only empty matches when the pattern generated an empty string.
+func (x *Xeger) singleMatch(s string) bool {
+	if !x.noise {
+		return true
+	}
+	n := 0
+	for _, loc := range x.search.FindAllStringIndex(s, -1) {
+		if loc[0] != loc[1] {
+			n++
+		}
+	}
+	return n == 1 || n == 0 && x.search.MatchString(s)
+}
+
+// surround wraps the match generated from start onwards in noise, going by
+// the anchors the walk passed at either end of it: none before a match
+// that began at a \A or after one that ended at a \z. Noise before a
+// match that began at a multiline ^ ends in a newline, and noise after
+// one that ended at a multiline $ starts with one, so the match still
+// sits on a line of its own. Going by the walk rather than the pattern
+// lets (?m)(?:x$|y) put noise straight after a y.
+func (g *generator) surround(start int) error {
+	end := len(g.buf)
+	match := string(g.buf[start:])
+	g.buf = g.buf[:start]
+	if !g.beginText {
+		if err := g.noise(); err != nil {
+			return err
+		}
+		if g.beginLine {
+			g.buf = append(g.buf, '\n')
+		}
+		g.shiftSpans(len(g.buf) - start)
+	}
+	g.buf = append(g.buf, match...)
+	if g.endText != end {
+		if g.endLine == end {
+			g.buf = append(g.buf, '\n')
+		}
+		return g.noise()
+	}
+	return nil
+}
+
+// anchor notes that the walk passed the anchor op, at the start of the
+// match or at the current end of the buffer. Without (?m) the parser
+// writes $ as \z with the WasDollar flag; RE2 gives both the same meaning,
+// the end of the text, so they are noted alike.
+func (g *generator) anchor(op syntax.Op) {
+	atStart := len(g.buf) == g.matchStart
+	switch op {
+	case syntax.OpBeginText:
+		g.beginText = g.beginText || atStart
+	case syntax.OpBeginLine:
+		g.beginLine = g.beginLine || atStart
+	case syntax.OpEndText:
+		g.endText = len(g.buf)
+	case syntax.OpEndLine:
+		g.endLine = len(g.buf)
+	}
+}
+
+// noise appends between 1 and maxNoiseLen random noise characters.
+func (g *generator) noise() error {
+	n, err := g.decide(DecisionRepeat, 1,
+		func() int { return 1 + g.rng.Intn(maxNoiseLen) },
+		func(n int) bool { return n >= 1 && n <= maxNoiseLen })
+	if err != nil {
+		return err
+	}
+	for ; n > 0; n-- {
+		c, err := g.decide(DecisionRune, int(noiseChars[0]),
+			func() int { return int(noiseChars[g.rng.Intn(len(noiseChars))]) },
+			func(c int) bool { return c < utf8.RuneSelf && strings.IndexByte(noiseChars, byte(c)) >= 0 })
+		if err != nil {
+			return err
+		}
+		g.buf = append(g.buf, byte(c))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package xeger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithSurroundingNoise(t *testing.T) {
+	var tests = []string{
+		`foo`,
+		`[0-9]{3}`,
+		`a(b|c)d`,
+		`x*`,
+	}
+
+	for _, pattern := range tests {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1), WithSurroundingNoise(true))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", pattern, err)
+			}
+			if iRe.regexp.MatchString(s) && pattern != `x*` {
+				t.Fatalf("%s: %q is only the match, with no noise", pattern, s)
+			}
+			var n int
+			for _, loc := range iRe.search.FindAllStringIndex(s, -1) {
+				if loc[0] != loc[1] {
+					n++
+				}
+			}
+			if n > 1 {
+				t.Fatalf("%s: noise in %q introduced extra matches", pattern, s)
+			}
+		}
+	}
+}
+
+func TestWithSurroundingNoiseAnchored(t *testing.T) {
+	iRe, err := NewInverseRegex(`^id-[0-9]{2}`, WithSeed(1), WithSurroundingNoise(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(s, "id-") {
+			t.Fatalf("%q: expected no noise before a ^ anchor", s)
+		}
+		if len(s) == len("id-00") {
+			t.Fatalf("%q: expected noise after the match", s)
+		}
+	}
+}
+
+func TestWithSurroundingNoiseMultiline(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Before  bool
+		After   bool
+	}{
+		{`(?m)^x`, true, false},
+		{`(?m)[0-9]{3}$`, false, true},
+		{`(?m)^(?:ab|cd)$`, true, true},
+		{`(?m:^)foo\z`, true, false},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithSurroundingNoise(true))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			loc := iRe.search.FindStringIndex(s)
+			if test.Before && (loc[0] == 0 || s[loc[0]-1] != '\n') {
+				t.Fatalf("%s: match in %q does not follow a newline", test.Pattern, s)
+			}
+			if test.After && (loc[1] == len(s) || s[loc[1]] != '\n') {
+				t.Fatalf("%s: match in %q is not followed by a newline", test.Pattern, s)
+			}
+		}
+	}
+}
+
+func TestWithSurroundingNoiseAnchorPerBranch(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		// Anchored reports whether a given match must sit at a line end.
+		Anchored func(m string) bool
+	}{
+		{`(?m)(?:x$|y)`, func(m string) bool { return m == "x" }},
+		{`(?m)[0-9]{2}(?:$|-)`, func(m string) bool { return !strings.HasSuffix(m, "-") }},
+		{`(?m)ab$\ncd$`, func(string) bool { return true }},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithSurroundingNoise(true))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		var anchored, free bool
+		for i := 0; i < 100; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			loc := iRe.search.FindStringIndex(s)
+			m := s[loc[0]:loc[1]]
+			if !test.Anchored(m) {
+				free = free || loc[1] < len(s) && s[loc[1]] != '\n'
+				continue
+			}
+			anchored = true
+			if loc[1] == len(s) || s[loc[1]] != '\n' {
+				t.Fatalf("%s: match %q in %q is not at a line end", test.Pattern, m, s)
+			}
+		}
+		if !anchored {
+			t.Errorf("%s: never generated an anchored match", test.Pattern)
+		}
+		if test.Pattern == `(?m)(?:x$|y)` && !free {
+			t.Errorf("%s: noise never directly followed an unanchored match", test.Pattern)
+		}
+	}
+}
+
+func TestWithSurroundingNoiseZeroWidth(t *testing.T) {
+	iRe, err := NewInverseRegex(`^$`, WithSeed(1), WithSurroundingNoise(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := iRe.GenerateValid(); s != "" || err != nil {
+		t.Errorf("got %q, %v, want an empty match with no noise", s, err)
+	}
+}
@@ -0,0 +1,213 @@
+package xeger
+
+import (
+	"fmt"
+	"math/big"
+	"regexp/syntax"
+)
+
+// GenerateOpCoverage returns a small set of strings that between them
+// visit every node of the pattern at least once: each branch of every
+// alternation, the content of each optional part, and so on. Each string
+// is generated with choices steered towards nodes not yet visited, and
+// only strings that visit something new are kept, so a|b(c)?d typically
+// needs two. This is a smoke test of the generator across the whole
+// pattern, and a starting corpus for fuzzing. Nodes that can never be
+// generated, such as the a of a{0}, are not required. It fails with
+// ErrRetryExhausted if too many strings in a row visit nothing new.
+func (x *Xeger) GenerateOpCoverage() ([]string, error) {
+	need := make(map[*syntax.Regexp]bool)
+	reachable(x.re, need)
+	if x.zeroWidth {
+		// the walk is skipped altogether
+		need = nil
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.visits = make(map[*syntax.Regexp]bool)
+	g.covered = make(map[*syntax.Regexp]bool)
+	var out []string
+	for misses := 0; len(out) == 0 || len(g.covered) < len(need); {
+		s, err := g.generate()
+		if err != nil {
+			return nil, err
+		}
+		n := len(g.covered)
+		for re := range g.visits {
+			if need[re] {
+				g.covered[re] = true
+			}
+		}
+		if len(g.covered) > n || len(out) == 0 {
+			out = append(out, s)
+			misses = 0
+			continue
+		}
+		if misses++; misses == x.maxRetries {
+			return nil, fmt.Errorf("%w: %d of %d nodes still unvisited after %d strings", ErrRetryExhausted, len(need)-len(g.covered), len(need), len(out)+misses)
+		}
+	}
+	return out, nil
+}
+
+// reachable adds to nodes every node of re that some match visits, which
+// excludes the content of a{0} and parts that can never match.
+func reachable(re *syntax.Regexp, nodes map[*syntax.Regexp]bool) {
+	if !canMatch(re) {
+		return
+	}
+	nodes[re] = true
+	if re.Op == syntax.OpRepeat && re.Max == 0 {
+		return
+	}
+	for _, sub := range re.Sub {
+		reachable(sub, nodes)
+	}
+}
+
+// canMatch reports whether re matches at least one string.
+func canMatch(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpCharClass:
+		return len(re.Rune) > 0
+	case syntax.OpStar, syntax.OpQuest:
+		return true
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return true
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if canMatch(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sub := range re.Sub {
+		if !canMatch(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// uncovered reports whether re or a node within it that can match has
+// been neither covered by earlier strings nor visited by this one.
+func (g *generator) uncovered(re *syntax.Regexp) bool {
+	if !canMatch(re) {
+		return false
+	}
+	if !g.covered[re] && !g.visits[re] {
+		return true
+	}
+	if re.Op == syntax.OpRepeat && re.Max == 0 {
+		return false
+	}
+	for _, sub := range re.Sub {
+		if g.uncovered(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// uncoveredBranch picks at random one of the branches of the alternation
+// re that contains uncovered nodes, or reports false if there is none.
+func (g *generator) uncoveredBranch(re *syntax.Regexp) (int, bool) {
+	var branches []int
+	for i, sub := range re.Sub {
+		if g.uncovered(sub) {
+			branches = append(branches, i)
+		}
+	}
+	if len(branches) == 0 {
+		return 0, false
+	}
+	return branches[g.rng.Intn(len(branches))], true
+}
+
+// GenerateUntilCoverage generates strings until they cover targetFraction
+// of the pattern's shapes, or maxSamples strings have been generated, and
+// returns them with the coverage achieved. A shape is one combination of
+// the branch taken at every alternation and whether each optional part,
+// such as x? or x*, is present, looking only at the first copy of each
+// repeat; (ab|cd)(ef|gh)? has the six shapes abef, abgh, ab, cdef, cdgh
+// and cd. Single-rune alternations such as a|b are char classes to the
+// parser and have a single shape. As with WithCoverageBias, each
+// alternation deals its branches without replacement, so that early
+// strings spread across the branches. It stops early on a generation
+// error, returning the strings so far.
+func (x *Xeger) GenerateUntilCoverage(targetFraction float64, maxSamples int) ([]string, float64) {
+	total, _ := new(big.Float).SetInt(shapes(x.re)).Float64()
+	if total == 0 {
+		return nil, 0
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.coverage = make(map[*syntax.Regexp]*dealer)
+	g.shape = []int{}
+	seen := make(map[string]bool)
+	var out []string
+	coverage := 0.0
+	for len(out) < maxSamples && coverage < targetFraction {
+		s, err := g.generate()
+		if err != nil {
+			break
+		}
+		out = append(out, s)
+		seen[fmt.Sprint(g.shape)] = true
+		coverage = float64(len(seen)) / total
+	}
+	return out, coverage
+}
+
+// shapes returns the number of shapes of re, as GenerateUntilCoverage
+// counts them.
+func shapes(re *syntax.Regexp) *big.Int {
+	if !canMatch(re) {
+		return new(big.Int)
+	}
+	switch re.Op {
+	case syntax.OpAlternate:
+		n := new(big.Int)
+		for _, sub := range re.Sub {
+			n.Add(n, shapes(sub))
+		}
+		return n
+	case syntax.OpConcat:
+		n := big.NewInt(1)
+		for _, sub := range re.Sub {
+			n.Mul(n, shapes(sub))
+		}
+		return n
+	case syntax.OpCapture, syntax.OpPlus, syntax.OpStar, syntax.OpQuest, syntax.OpRepeat:
+		if re.Op == syntax.OpRepeat && re.Max == 0 {
+			return big.NewInt(1)
+		}
+		n := shapes(re.Sub[0])
+		if optional(re) {
+			n.Add(n, big.NewInt(1))
+		}
+		return n
+	}
+	return big.NewInt(1)
+}
+
+// optional reports whether re is a quantifier that may leave out its
+// content or include it.
+func optional(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpQuest:
+		return true
+	case syntax.OpRepeat:
+		return re.Min == 0 && re.Max != 0
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package xeger
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+)
+
+func TestGenerateOpCoverage(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Max     int
+	}{
+		{`abc`, 1},
+		{`a|b(cd)?e`, 2},
+		{`(?:red|green|blue)-(?:one|two)`, 3},
+		{`x(?:ab|cd|ef)*y`, 3},
+		{`(?:p|q{0})z`, 2},
+		{`^$`, 1},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		out, err := iRe.GenerateOpCoverage()
+		if err != nil {
+			t.Fatalf("%s: %v", test.Pattern, err)
+		}
+		if len(out) == 0 || len(out) > test.Max {
+			t.Errorf("%s: got %d strings %q, want 1 to %d", test.Pattern, len(out), out, test.Max)
+		}
+		for _, s := range out {
+			if !iRe.regexp.MatchString(s) {
+				t.Errorf("%s: %q does not match", test.Pattern, s)
+			}
+		}
+	}
+}
+
+func TestGenerateOpCoverageVisitsAll(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<k>[a-z]+)=(?:"(?:[^"]|\\")*"|[0-9]+|true|false)(?:;|,)?`, WithSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := iRe.GenerateOpCoverage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, s := range out {
+		sub := iRe.regexp.FindStringSubmatch(s)
+		if sub == nil {
+			t.Fatalf("%q does not match", s)
+		}
+		switch val := s[len(sub[1])+1:]; {
+		case len(val) > 0 && val[0] == '"':
+			seen["string"] = true
+		case len(val) > 0 && val[0] >= '0' && val[0] <= '9':
+			seen["number"] = true
+		case len(val) >= 4 && val[:4] == "true":
+			seen["true"] = true
+		case len(val) >= 5 && val[:5] == "false":
+			seen["false"] = true
+		}
+	}
+	if len(seen) != 4 || len(out) > 6 {
+		t.Errorf("got %d strings %q covering %v", len(out), out, seen)
+	}
+}
+
+func TestVisitsSkipLiteral(t *testing.T) {
+	iRe, err := NewInverseRegex(`ab?`, WithQuestProbability(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// with the b covered already, b? is left to its usual draw and skipped
+	b := iRe.re.Sub[1].Sub[0]
+	g := iRe.newGenerator(iRe.rng)
+	g.visits = make(map[*syntax.Regexp]bool)
+	g.covered = map[*syntax.Regexp]bool{b: true}
+	if s, err := g.generate(); err != nil || s != "a" {
+		t.Fatalf("got %q, %v", s, err)
+	}
+	if g.visits[b] {
+		t.Errorf("%s marked visited", b)
+	}
+}
+
+func TestGenerateOpCoverageExhausted(t *testing.T) {
+	// the pinned capture's content is never generated
+	iRe, err := NewInverseRegex(`(?P<n>[0-9])`, WithSeed(1), WithMaxRetries(5), WithCaptureTemplate("n", "7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.GenerateOpCoverage(); !errors.Is(err, ErrRetryExhausted) {
+		t.Errorf("got %v, want ErrRetryExhausted", err)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	re, err := syntax.Parse(`a{0}b|[^\x00-\x{10FFFF}]c|d`, syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make(map[*syntax.Regexp]bool)
+	reachable(re, nodes)
+	// the alternation, a{0}b, a{0}, b and d
+	if len(nodes) != 5 {
+		t.Errorf("got %d reachable nodes, want 5", len(nodes))
+	}
+}
+
+func TestGenerateUntilCoverage(t *testing.T) {
+	iRe, err := NewInverseRegex(`(ab|cd)(ef|gh)?`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, coverage := iRe.GenerateUntilCoverage(1, 500)
+	if coverage != 1 {
+		t.Fatalf("got coverage %v after %d strings", coverage, len(out))
+	}
+	seen := make(map[string]bool)
+	for _, s := range out {
+		seen[s] = true
+	}
+	for _, want := range []string{"abef", "abgh", "ab", "cdef", "cdgh", "cd"} {
+		if !seen[want] {
+			t.Errorf("shape %q missing from %q", want, out)
+		}
+	}
+
+	out, coverage = iRe.GenerateUntilCoverage(0.5, 500)
+	if coverage < 0.5 || len(out) > 6 {
+		t.Errorf("got coverage %v from %d strings, want at least 0.5 quickly", coverage, len(out))
+	}
+
+	iRe, err = NewInverseRegex(`(?:get|put|post)-(?:x|yz){1,3}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, coverage = iRe.GenerateUntilCoverage(1, 2)
+	if len(out) != 2 || coverage != 2.0/6 {
+		t.Errorf("got coverage %v from %d strings, want 1/3 from the 2 allowed", coverage, len(out))
+	}
+}
+
+func TestShapes(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    int64
+	}{
+		{`abc`, 1},
+		{`[a-z]+`, 1},
+		{`(ab|cd)(ef|gh)?`, 6},
+		{`(?:ab|cd)*`, 3},
+		{`(?:ab|cd){2,5}`, 2},
+		{`(?:ab|cd){0}x`, 1},
+		{`(?:ab|cd|[^\x00-\x{10FFFF}])`, 2},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if got := shapes(iRe.re); got.Int64() != test.Want {
+			t.Errorf("%s: got %v shapes, want %d", test.Pattern, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package xeger
+
+import "regexp/syntax"
+
+// WithIncludeAllOptionals makes every string exercise all the optional
+// parts of the pattern, giving its most complete form: each ? is taken,
+// each * and other quantifier with a minimum of zero repeats at least
+// once, and an alternation with empty branches, such as (a|b|), chooses
+// among the others. It is the structural counterpart of WithCompactBias
+// at full strength, and overrides it where the two disagree. Counts past
+// the first are chosen as usual.
+func WithIncludeAllOptionals(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.allOptionals = enabled
+		x.nonEmpty = nil
+		if enabled {
+			x.nonEmpty = make(map[*syntax.Regexp][]int)
+			nonEmptyBranches(x.re, x.nonEmpty)
+		}
+		return nil
+	}
+}
+
+// nonEmptyBranches records in nonEmpty, for each alternation in re with
+// both branches that only match the empty string and branches that do
+// not, the indexes of the latter.
+func nonEmptyBranches(re *syntax.Regexp, nonEmpty map[*syntax.Regexp][]int) {
+	if re.Op == syntax.OpAlternate {
+		var fill []int
+		for i, sub := range re.Sub {
+			if _, max := lengthBounds(sub); max != 0 {
+				fill = append(fill, i)
+			}
+		}
+		if len(fill) > 0 && len(fill) < len(re.Sub) {
+			nonEmpty[re] = fill
+		}
+	}
+	for _, sub := range re.Sub {
+		nonEmptyBranches(sub, nonEmpty)
+	}
+}
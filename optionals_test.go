@@ -0,0 +1,28 @@
+package xeger
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestWithIncludeAllOptionals(t *testing.T) {
+	complete := regexp.MustCompile(`^https://[a-z]+(?:\.[a-z]+)+:[0-9]+/[xy]$`)
+	for _, opts := range [][]Option{
+		{WithIncludeAllOptionals(true)},
+		{WithIncludeAllOptionals(true), WithCompactBias(1)},
+	} {
+		iRe, err := NewInverseRegex(`(?:https?://)?[a-z]+(?:\.[a-z]+)*(?::[0-9]+)?(/x|/y|)`, append(opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !complete.MatchString(s) {
+				t.Fatalf("%q leaves out an optional part", s)
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// An Option configures a Xeger at construction time.
+type Option func(*Xeger) error
+
+// WithSeed seeds the random source so that a Xeger produces the same
+// sequence of strings on every run.
+func WithSeed(seed int64) Option {
+	return func(x *Xeger) error {
+		x.seed, x.seeded = seed, true
+		return nil
+	}
+}
+
+// defaultMaxRetries is how many attempts are made to satisfy the configured
+// checks before generation gives up.
+const defaultMaxRetries = 100
+
+// WithMaxRetries sets how many times generation is attempted before giving
+// up on a string that satisfies the configured constraints.
+func WithMaxRetries(n int) Option {
+	return func(x *Xeger) error {
+		if n < 1 {
+			return fmt.Errorf("xeger: max retries must be positive, got %d", n)
+		}
+		x.maxRetries = n
+		return nil
+	}
+}
+
+// WithLengthRange re-rolls generated strings until their length in runes
+// falls within [min, max]. It is an error if Analyze shows the pattern can
+// never produce a string of such a length.
+func WithLengthRange(min, max int) Option {
+	return func(x *Xeger) error {
+		if min < 0 || max < min {
+			return fmt.Errorf("xeger: invalid length range [%d, %d]", min, max)
+		}
+		a := x.Analyze()
+		if max < a.MinLen || (a.MaxLen != -1 && min > a.MaxLen) {
+			return fmt.Errorf("%w: range [%d, %d] against pattern lengths [%d, %d]", ErrLengthInfeasible, min, max, a.MinLen, a.MaxLen)
+		}
+		x.checks = append(x.checks, func(s string) bool {
+			n := utf8.RuneCountInString(s)
+			return n >= min && n <= max
+		})
+		return nil
+	}
+}
+
+// WithMaxLength bounds generated strings to at most n runes. Unlike
+// WithLengthRange, which only re-rolls strings that are too long, it sets
+// a budget shared by the whole walk: each quantifier chooses its count as
+// usual but redraws from the counts leaving room for the minimum of what
+// follows, and each alternation chooses among the branches that still
+// fit, so nested quantifiers such as (a{2,3}){2,3} cut their inner counts
+// when the outer ones are large. Strings still over the budget, as where
+// a quantifier's copies vary in length, are re-rolled. It is an error if
+// Analyze shows every string of the pattern is longer than n.
+func WithMaxLength(n int) Option {
+	return func(x *Xeger) error {
+		if n < 0 {
+			return fmt.Errorf("xeger: max length must not be negative, got %d", n)
+		}
+		if a := x.Analyze(); n < a.MinLen {
+			return fmt.Errorf("%w: max length %d against pattern lengths [%d, %d]", ErrLengthInfeasible, n, a.MinLen, a.MaxLen)
+		}
+		x.maxLength = n
+		x.checks = append(x.checks, func(s string) bool {
+			return utf8.RuneCountInString(s) <= n
+		})
+		return nil
+	}
+}
+
+// WithMustNotMatch re-rolls generated strings until re does not match
+// them, for producing counterexamples that match one pattern but not
+// another. re is applied as given, so it rejects strings it matches
+// anywhere unless anchored. Some combinations can never be satisfied, such
+// as a pattern and a superset of it; those exhaust the retry limit and
+// fail with ErrRetryExhausted, which CanGenerate reports up front.
+func WithMustNotMatch(re *regexp.Regexp) Option {
+	return func(x *Xeger) error {
+		if re == nil {
+			return fmt.Errorf("xeger: nil regexp for WithMustNotMatch")
+		}
+		x.checks = append(x.checks, func(s string) bool {
+			return !re.MatchString(s)
+		})
+		return nil
+	}
+}
+
+// WithReservedWords re-rolls generated strings that equal one of words,
+// such as keeping the language keywords out of identifiers generated from
+// [a-zA-Z_][a-zA-Z0-9_]*. Only exact matches are rejected, so with for
+// reserved, fork and form may still be generated. Reserving every string a
+// pattern can produce exhausts the retry limit with ErrRetryExhausted.
+func WithReservedWords(words []string) Option {
+	return func(x *Xeger) error {
+		reserved := make(map[string]bool, len(words))
+		for _, w := range words {
+			reserved[w] = true
+		}
+		x.checks = append(x.checks, func(s string) bool {
+			return !reserved[s]
+		})
+		return nil
+	}
+}
+
+// WithRequired re-rolls generated strings until they contain every one of
+// substrings, for targeting inputs at a particular downstream code path,
+// as the positive counterpart of WithMustNotMatch. A substring the
+// pattern can never produce is an error up front: one longer than every
+// match, wrapping ErrLengthInfeasible, or one using a rune generation never
+// emits for the pattern. Otherwise a substring that can only rarely occur,
+// or not at all in the required place, exhausts the retry limit with
+// ErrRetryExhausted.
+func WithRequired(substrings []string) Option {
+	return func(x *Xeger) error {
+		a := x.Analyze()
+		runes := emittable(x.re)
+		for _, sub := range substrings {
+			if n := utf8.RuneCountInString(sub); a.MaxLen != -1 && n > a.MaxLen {
+				return fmt.Errorf("%w: required %q has %d runes against pattern lengths [%d, %d]", ErrLengthInfeasible, sub, n, a.MinLen, a.MaxLen)
+			}
+			for _, r := range sub {
+				if !inRanges(runes, r) {
+					return fmt.Errorf("xeger: required %q has %q, which %s never generates", sub, r, x.re)
+				}
+			}
+		}
+		required := append([]string(nil), substrings...)
+		x.checks = append(x.checks, func(s string) bool {
+			for _, sub := range required {
+				if !strings.Contains(s, sub) {
+					return false
+				}
+			}
+			return true
+		})
+		return nil
+	}
+}
+
+// WithEdgeBias makes char class picks choose the first or last rune of one
+// of the class's ranges with probability p, such as 'a' or 'z' for [a-z].
+// This helps surface off-by-one errors in downstream range checks. With p
+// of 0 every rune in the class is equally likely.
+func WithEdgeBias(p float64) Option {
+	return func(x *Xeger) error {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("xeger: edge bias must be in [0, 1], got %v", p)
+		}
+		x.edgeBias = p
+		return nil
+	}
+}
+
+// defaultQuestProbability is how often a ? includes its content unless
+// configured otherwise.
+const defaultQuestProbability = 0.5
+
+// WithQuestProbability sets the probability p that an optional part, the
+// x of x?, is generated rather than left out. The default of 0.5 makes
+// both equally likely; a high p suits generating records whose optional
+// fields are usually present. Other quantifiers, including x{0,1}, choose
+// their counts as usual.
+func WithQuestProbability(p float64) Option {
+	return func(x *Xeger) error {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("xeger: quest probability must be in [0, 1], got %v", p)
+		}
+		x.questProb = p
+		return nil
+	}
+}
+
+// WithDistinctAlternatesInRepeat makes each repetition of a repeat choose a
+// different alternation branch (or char class rune) from the ones before
+// it, like dealing cards without replacement. When there are fewer choices
+// than repetitions, the choices are reshuffled once all have been used.
+// Choices are tracked per node of the parsed tree, and the parser factors
+// common prefixes out of alternations (foo|bar|baz becomes foo|ba[rz]), so
+// branches sharing a prefix are only distinct in their factored parts.
+func WithDistinctAlternatesInRepeat(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.distinctAlternates = enabled
+		return nil
+	}
+}
+
+// WithMaximizeVariety makes each generated string exercise as many
+// choices as it can: every time the walk meets an alternation, char class
+// or quantifier again within one string, such as the (get|put|post) in
+// (?:(get|put|post) [0-9]{1,3};)+, it picks a branch, rune or count that
+// the string has not used there yet, starting over once all have been
+// used. This gives single rich samples for stress-testing parsers; unlike
+// WithCoverageBias, nothing carries over from one string to the next, and
+// unlike WithDistinctAlternatesInRepeat, the choices are tracked across
+// the whole string rather than within each repeat.
+func WithMaximizeVariety(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.variety = enabled
+		return nil
+	}
+}
+
+// WithWhitespaceRune makes char classes made up only of whitespace, such as
+// \s or [ \t], always emit r instead of a random whitespace rune, so that
+// \s+ gives runs of plain spaces with WithWhitespaceRune(' '). Classes that
+// admit anything else are unaffected, as are whitespace classes that do
+// not contain r, such as [\t\n] with r a space. It is an error if r is not
+// whitespace.
+func WithWhitespaceRune(r rune) Option {
+	return func(x *Xeger) error {
+		if !unicode.IsSpace(r) {
+			return fmt.Errorf("xeger: %q is not a whitespace rune", r)
+		}
+		x.whitespaceRune = r
+		return nil
+	}
+}
+
+// defaultMaxDepth matches the nesting limit of the regexp parser, so that
+// by default every parsable pattern can be generated.
+const defaultMaxDepth = 1000
+
+// WithMaxDepth limits how deeply generation recurses into the parsed
+// pattern to n levels, counting every alternation, capture, repeat and
+// concatenation along the way. Deeper patterns fail to generate with
+// ErrDepthExceeded.
+func WithMaxDepth(n int) Option {
+	return func(x *Xeger) error {
+		if n < 1 {
+			return fmt.Errorf("xeger: max depth must be positive, got %d", n)
+		}
+		x.maxDepth = n
+		return nil
+	}
+}
+
+// WithCoverageBias makes successive calls prefer alternation branches they
+// have not chosen before, so that a sequence of Generate calls exercises
+// every branch of every alternation as early as possible. Each alternation
+// deals its branches without replacement across calls, starting over
+// once all have been chosen. Only calls drawing from the instance's own
+// random source, such as Generate and GenerateN, take part; GenerateWith,
+// GenerateAt and other calls with their own source are unaffected, which
+// keeps them reproducible. Single-rune alternations such as a|b are char
+// classes to the parser and are not covered.
+func WithCoverageBias(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.coverage = nil
+		if enabled {
+			x.coverage = make(map[*syntax.Regexp]*dealer)
+		}
+		return nil
+	}
+}
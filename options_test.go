@@ -0,0 +1,363 @@
+package xeger
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWithLengthRange(t *testing.T) {
+	iRe, err := NewInverseRegex(`abc`, WithLengthRange(2, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := iRe.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(s); n < 2 || n > 4 {
+		t.Errorf("got %q with length %d outside [2, 4]", s, n)
+	}
+}
+
+func TestWithLengthRangeInfeasible(t *testing.T) {
+	var tests = []struct {
+		Pattern  string
+		Min, Max int
+	}{
+		{`abc`, 4, 5},
+		{`abc`, 0, 2},
+		{`[0-9]{3,5}`, 6, 10},
+		{`a+`, 3, 1},
+		{`a+`, -1, 1},
+	}
+
+	for _, test := range tests {
+		_, err := NewInverseRegex(test.Pattern, WithLengthRange(test.Min, test.Max))
+		if err == nil {
+			t.Errorf("%s: expected an error for range [%d, %d]", test.Pattern, test.Min, test.Max)
+		}
+	}
+}
+
+func TestWithLengthRangeRetryExhausted(t *testing.T) {
+	iRe, err := NewInverseRegex(`abc|abcdef`, WithLengthRange(4, 5), WithMaxRetries(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := iRe.Generate(); err == nil {
+		t.Errorf("expected retries to be exhausted, got %q", s)
+	}
+}
+
+func TestWithMaxLength(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Max     int
+	}{
+		{`(a{2,3}){2,3}`, 7},
+		{`(?:(?:[a-z]{1,10}-){1,10};){1,10}`, 30},
+		{`(?:x+|yyyyy)+z`, 4},
+		{`[0-9]+(?:\.[0-9]+)?`, 3},
+	}
+
+	for _, test := range tests {
+		// few retries, so the budget must be kept during the walk
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithMaxLength(test.Max), WithMaxReps(50), WithMaxRetries(2))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		longest := 0
+		for i := 0; i < 200; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			n := utf8.RuneCountInString(s)
+			if n > test.Max {
+				t.Fatalf("%s: %q is longer than %d", test.Pattern, s, test.Max)
+			}
+			longest = max(longest, n)
+		}
+		if longest != test.Max {
+			t.Errorf("%s: longest string had %d runes, want the full budget of %d", test.Pattern, longest, test.Max)
+		}
+	}
+
+	if _, err := NewInverseRegex(`a{5}`, WithMaxLength(3)); !errors.Is(err, ErrLengthInfeasible) {
+		t.Errorf("got error %v, want ErrLengthInfeasible", err)
+	}
+}
+
+func TestWithRequired(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?:[a-z]{2}|admin|root)(?:-(?:admin|x))?`, WithSeed(1), WithRequired([]string{"admin"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(s, "admin") {
+			t.Fatalf("%q lacks the required admin", s)
+		}
+	}
+
+	var tests = []struct {
+		Pattern  string
+		Required []string
+	}{
+		{`[a-z]{3}`, []string{"abcd"}},
+		{`[a-z]+`, []string{"a1"}},
+		{`.+`, []string{"é"}},
+		{`(?i)a+`, []string{"Ab", "a"}},
+	}
+	for _, test := range tests {
+		if _, err := NewInverseRegex(test.Pattern, WithRequired(test.Required)); err == nil {
+			t.Errorf("%s: expected an error requiring %q", test.Pattern, test.Required)
+		}
+	}
+	if _, err := NewInverseRegex(`(?i)a+`, WithRequired([]string{"Aa"})); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	iRe, err = NewInverseRegex(`[a-z]{8}`, WithSeed(1), WithRequired([]string{"zzzz"}), WithMaxRetries(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.Generate(); !errors.Is(err, ErrRetryExhausted) {
+		t.Errorf("got error %v, want ErrRetryExhausted", err)
+	}
+}
+
+func TestWithEdgeBias(t *testing.T) {
+	iRe, err := NewInverseRegex(`[0-9a-f]`, WithSeed(1), WithEdgeBias(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		s, err := iRe.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch s {
+		case "0", "9", "a", "f":
+		default:
+			t.Fatalf("got %q, want a range endpoint", s)
+		}
+	}
+
+	iRe, err = NewInverseRegex(`[a-z]`, WithSeed(1), WithEdgeBias(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 500; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[s] = true
+	}
+	if len(seen) < 20 {
+		t.Errorf("expected a uniform spread over [a-z], saw only %d runes", len(seen))
+	}
+
+	if _, err := NewInverseRegex(`[a-z]`, WithEdgeBias(1.5)); err == nil {
+		t.Errorf("expected an error for an out of range probability")
+	}
+}
+
+func TestWithWhitespaceRune(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Rune    rune
+		Valid   func(string) bool
+	}{
+		{`a\s+b`, ' ', func(s string) bool { return strings.Trim(s[1:len(s)-1], " ") == "" }},
+		{`[ \t]{4}`, '\t', func(s string) bool { return s == "\t\t\t\t" }},
+		{`[[:space:]]`, '\n', func(s string) bool { return s == "\n" }},
+		// classes admitting non-whitespace keep their full range
+		{`[\sx]{40}`, ' ', func(s string) bool { return strings.Contains(s, "x") }},
+		// whitespace classes without the rune fall back to a random pick
+		{`[\t\n]{40}`, ' ', func(s string) bool { return strings.Contains(s, "\t") && strings.Contains(s, "\n") }},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithWhitespaceRune(test.Rune))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 20; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if !test.Valid(s) {
+				t.Fatalf("%s: got %q", test.Pattern, s)
+			}
+		}
+	}
+
+	if _, err := NewInverseRegex(`\s`, WithWhitespaceRune('x')); err == nil {
+		t.Errorf("expected an error for a non-whitespace rune")
+	}
+}
+
+func TestWithMustNotMatch(t *testing.T) {
+	not := regexp.MustCompile(`^[a-f]+$`)
+	iRe, err := NewInverseRegex(`[a-h]{1,3}`, WithSeed(1), WithMustNotMatch(not))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if not.MatchString(s) {
+			t.Fatalf("%q matches %s", s, not)
+		}
+	}
+
+	iRe, err = NewInverseRegex(`[a-c]{3}`, WithMustNotMatch(regexp.MustCompile(`[a-z]`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iRe.CanGenerate(); !errors.Is(err, ErrRetryExhausted) {
+		t.Errorf("got %v, want ErrRetryExhausted for an unsatisfiable combination", err)
+	}
+}
+
+func TestWithReservedWords(t *testing.T) {
+	keywords := []string{"if", "in", "do", "go", "or", "for", "int"}
+	iRe, err := NewInverseRegex(`[idfgo][fnor]t?`, WithSeed(1), WithReservedWords(keywords))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 300; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[s] = true
+	}
+	for _, k := range keywords {
+		if seen[k] {
+			t.Errorf("generated the reserved word %q", k)
+		}
+	}
+	if !seen["ift"] || !seen["fo"] {
+		t.Errorf("expected near misses of reserved words, got %v", seen)
+	}
+
+	iRe, err = NewInverseRegex(`[a-zA-Z_][a-zA-Z0-9_]{0,2}`, WithSeed(2), WithReservedWords(keywords))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident := regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	for i := 0; i < 100; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ident.MatchString(s) {
+			t.Fatalf("%q is not an identifier", s)
+		}
+	}
+
+	iRe, err = NewInverseRegex(`(?:if|do)`, WithReservedWords(keywords))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iRe.CanGenerate(); !errors.Is(err, ErrRetryExhausted) {
+		t.Errorf("got %v, want ErrRetryExhausted with every match reserved", err)
+	}
+}
+
+func TestWithQuestProbability(t *testing.T) {
+	var tests = []struct {
+		P        float64
+		Lo, High int
+	}{
+		{0, 0, 0},
+		{0.9, 800, 980},
+		{0.5, 400, 600},
+		{1, 1000, 1000},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(`id(?:,name)?`, WithSeed(1), WithQuestProbability(test.P))
+		if err != nil {
+			t.Fatal(err)
+		}
+		present := 0
+		for i := 0; i < 1000; i++ {
+			s, err := iRe.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s == "id,name" {
+				present++
+			}
+		}
+		if present < test.Lo || present > test.High {
+			t.Errorf("p=%v: optional part present %d times in 1000, want [%d, %d]", test.P, present, test.Lo, test.High)
+		}
+	}
+
+	for _, p := range []float64{-0.1, 1.5} {
+		if _, err := NewInverseRegex(`a?`, WithQuestProbability(p)); err == nil {
+			t.Errorf("p=%v: expected an error", p)
+		}
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	nested := func(open, close string, n int) string {
+		return strings.Repeat(open, n) + "a" + strings.Repeat(close, n)
+	}
+	// branching nests alternations whose every branch goes n levels deep
+	var branching func(n int) string
+	branching = func(n int) string {
+		if n == 0 {
+			return "a"
+		}
+		sub := branching(n - 1)
+		return "(?:x" + sub + "|y" + sub + ")"
+	}
+	var tests = []struct {
+		Pattern string
+		Depth   int
+		Fails   bool
+	}{
+		{nested("(", ")", 50), 20, true},
+		{nested("(", ")", 50), 60, false},
+		{branching(12), 10, true},
+		{branching(12), 100, false},
+		{nested("(?:x", ")+", 30), 20, true},
+		{`abc`, 1, false},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithMaxDepth(test.Depth))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		_, err = iRe.Generate()
+		if err != nil && !errors.Is(err, ErrDepthExceeded) {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if failed := err != nil; failed != test.Fails {
+			t.Errorf("%.40s: depth %d: got failure %v, want %v", test.Pattern, test.Depth, failed, test.Fails)
+		}
+	}
+
+	if _, err := NewInverseRegex(`a`, WithMaxDepth(0)); err == nil {
+		t.Error("expected an error for a zero depth")
+	}
+}
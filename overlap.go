@@ -0,0 +1,64 @@
+package xeger
+
+import "math/big"
+
+// maxOverlapExpansion is the most strings Overlap enumerates from a finite
+// pattern to give an exact answer.
+const maxOverlapExpansion = 1 << 16
+
+// overlapSamples is how many strings Overlap generates from each pattern
+// when it has to fall back on sampling.
+const overlapSamples = 1000
+
+// Overlap reports whether some string matches both patterns a and b, such
+// as a catch-all fixture pattern that also matches a more specific one.
+// When either pattern is finite with at most 65536 matches, they are all
+// checked against the other pattern and the answer is exact. Otherwise it
+// is a heuristic: a sample of 1000 strings generated from each pattern,
+// with a fixed seed, is checked against the other. A true answer always
+// comes with a string matching both, but false only means no such string
+// was found, so patterns overlapping only in rare strings, like .* and
+// [0-9]{20}, may be reported as disjoint. Errors are those of parsing
+// either pattern, or of generating from it.
+func Overlap(a, b string) (bool, error) {
+	xa, err := NewInverseRegex(a, WithSeed(1))
+	if err != nil {
+		return false, err
+	}
+	xb, err := NewInverseRegex(b, WithSeed(1))
+	if err != nil {
+		return false, err
+	}
+	for _, p := range [][2]*Xeger{{xa, xb}, {xb, xa}} {
+		if all, ok := p[0].expansion(); ok {
+			for _, s := range all {
+				if p[0].regexp.MatchString(s) && p[1].regexp.MatchString(s) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+	for _, p := range [][2]*Xeger{{xa, xb}, {xb, xa}} {
+		for i := 0; i < overlapSamples; i++ {
+			s, err := p[0].Generate()
+			if err != nil {
+				return false, err
+			}
+			if p[0].regexp.MatchString(s) && p[1].regexp.MatchString(s) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// expansion returns every string the pattern can generate, or false if it
+// is infinite or has more than maxOverlapExpansion of them.
+func (x *Xeger) expansion() ([]string, bool) {
+	n, ok := x.CountMatches()
+	if !ok || n.Cmp(big.NewInt(maxOverlapExpansion)) > 0 {
+		return nil, false
+	}
+	return expand(x.re), true
+}
@@ -0,0 +1,71 @@
+package xeger
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestOverlap(t *testing.T) {
+	var tests = []struct {
+		A, B string
+		Want bool
+	}{
+		// exact, with at least one finite side
+		{`[a-c]{2}`, `b[b-z]`, true},
+		{`[a-c]{2}`, `[d-f]{2}`, false},
+		{`(?i)get|put`, `GET`, true},
+		{`[0-9]{4}`, `[0-9]+x`, false},
+		{`[0-9]{4}`, `[0-9]+`, true},
+		{`\bfoo\b-`, `foo-`, true},
+		{`x\b`, `x`, true},
+		// sampled
+		{`[a-z]+@[a-z]+\.com`, `.*@example\.com`, true},
+		{`[a-z]+[0-9]*`, `[a-c]+`, true},
+		{`a+`, `b+`, false},
+		{`a\bb.*`, `ab.*`, false},
+	}
+
+	for _, test := range tests {
+		got, err := Overlap(test.A, test.B)
+		if err != nil {
+			t.Fatalf("%s, %s: unexpected error %v", test.A, test.B, err)
+		}
+		if got != test.Want {
+			t.Errorf("Overlap(%s, %s) = %v, want %v", test.A, test.B, got, test.Want)
+		}
+	}
+
+	if _, err := Overlap(`a(`, `a`); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`[ab][01]`, "a0 a1 b0 b1"},
+		{`x(?:y|z)?`, "x xy xz"},
+		{`(?i)ab`, "AB Ab aB ab"},
+		{`a{1,3}`, "a aa aaa"},
+		{`(?:ab|a){2}`, "aa aab aba abab"},
+		{`^(?:)$`, ""},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got := expand(iRe.re)
+		sort.Strings(got)
+		if strings.Join(got, " ") != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+		if n, _ := iRe.CountMatches(); n.Int64() != int64(len(got)) {
+			t.Errorf("%s: expanded %d strings, CountMatches says %v", test.Pattern, len(got), n)
+		}
+	}
+}
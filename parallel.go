@@ -0,0 +1,53 @@
+package xeger
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// WithPooledRNG makes Generate draw from a pool of random sources rather
+// than the instance's own, so that goroutines calling it concurrently do
+// not contend for a lock. The k'th source the pool creates is seeded as
+// GenerateAt(k-1) would be under WithSeed(baseSeed), so every string
+// comes from one of a fixed family of streams determined by baseSeed.
+// Which stream serves which call is up to the pool, though, and the pool
+// may drop idle sources at any time in favour of new ones, so the
+// sequence of strings is not reproducible, even from a single goroutine;
+// use GenerateAt or GenerateNParallel where it must be. The pool serves
+// Generate and the methods built on it, such as GenerateValid and
+// GenerateN; others, such as GenerateWithCaptures, still draw from the
+// instance's source under its lock. Calls served by the pool take no part
+// in WithCoverageBias.
+func WithPooledRNG(baseSeed int64) Option {
+	return func(x *Xeger) error {
+		var created atomic.Uint64
+		x.pool = &sync.Pool{New: func() any {
+			return rand.New(rand.NewSource(subSeed(baseSeed, created.Add(1))))
+		}}
+		return nil
+	}
+}
+
+// GenerateNParallel generates n strings using the given number of worker
+// goroutines. Element i is always GenerateAt(i), so the output is identical
+// regardless of the worker count or scheduling. Elements whose generation
+// fails are left empty.
+func (x *Xeger) GenerateNParallel(n, workers int) []string {
+	if workers < 1 {
+		workers = 1
+	}
+	out := make([]string, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				out[i], _ = x.GenerateAt(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,108 @@
+package xeger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestGenerateNParallelStableOrder(t *testing.T) {
+	iRe, err := NewInverseRegex(`foo.*`, WithSeed(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := iRe.GenerateNParallel(50, 1)
+	for _, workers := range []int{0, 2, 3, 8, 100} {
+		got := iRe.GenerateNParallel(50, workers)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("workers=%d: index %d got %q, want %q", workers, i, got[i], want[i])
+			}
+		}
+	}
+	for i, s := range want {
+		at, err := iRe.GenerateAt(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if at != s {
+			t.Errorf("index %d: GenerateAt gave %q, GenerateNParallel gave %q", i, at, s)
+		}
+	}
+}
+
+func BenchmarkGenerateNParallel(b *testing.B) {
+	iRe, err := NewInverseRegex(`^[0-9a-z]+\[[0-9]{3,5}\]$`, WithSeed(1))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				iRe.GenerateNParallel(1000, workers)
+			}
+		})
+	}
+}
+
+func TestWithPooledRNG(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{4}-[0-9]{3}`, WithSeed(7), WithPooledRNG(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pool's first source is seeded as GenerateAt(0)
+	first, err := iRe.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := iRe.GenerateAt(0); first != want {
+		t.Errorf("first pooled string %q, want GenerateAt(0) = %q", first, want)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				s, err := iRe.Generate()
+				if err == nil && !iRe.regexp.MatchString(s) {
+					err = fmt.Errorf("%q does not match", s)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func BenchmarkGeneratePooled(b *testing.B) {
+	for _, pooled := range []bool{false, true} {
+		opts := []Option{WithSeed(1)}
+		if pooled {
+			opts = append(opts, WithPooledRNG(1))
+		}
+		iRe, err := NewInverseRegex(`^[0-9a-z]+\[[0-9]{3,5}\]$`, opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("pooled=%t", pooled), func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := iRe.Generate(); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
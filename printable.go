@@ -0,0 +1,75 @@
+package xeger
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"unicode"
+)
+
+// WithPrintable makes char classes and . prefer printable runes, as
+// unicode.IsPrint defines them, keeping control characters and other
+// invisible runes out of the output wherever the pattern offers a
+// printable alternative. It is a preference that yields to necessity: a
+// class that only allows unprintable runes, such as [\x00-\x1f] in a
+// protocol pattern, still emits them, as do literals like \x07, and a .
+// that matches newlines no longer emits one. Use WithPrintableStrict to
+// reject such patterns instead.
+func WithPrintable(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.printable = enabled
+		return nil
+	}
+}
+
+// WithPrintableStrict is like WithPrintable, but makes it an error for the
+// pattern to force an unprintable rune, through a char class with no
+// printable runes or a literal such as \t. The whole pattern is checked
+// up front, including parts such as an optional group that a given string
+// might skip, and the error wraps ErrUnprintable.
+func WithPrintableStrict(enabled bool) Option {
+	return func(x *Xeger) error {
+		if enabled {
+			if re := unprintable(x.re); re != nil {
+				return fmt.Errorf("%w: %s in %s", ErrUnprintable, re, x.re)
+			}
+		}
+		x.printable = enabled
+		return nil
+	}
+}
+
+// unprintable returns the first node of re that can only produce
+// unprintable runes, or nil if there is none.
+func unprintable(re *syntax.Regexp) *syntax.Regexp {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if !unicode.IsPrint(r) {
+				return re
+			}
+		}
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 && !hasPrintable(re.Rune) {
+			return re
+		}
+	}
+	for _, sub := range re.Sub {
+		if u := unprintable(sub); u != nil {
+			return u
+		}
+	}
+	return nil
+}
+
+// hasPrintable reports whether ranges, a list of inclusive lo, hi pairs,
+// contains a printable rune.
+func hasPrintable(ranges []rune) bool {
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if unicode.IsPrint(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
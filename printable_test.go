@@ -0,0 +1,68 @@
+package xeger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestWithPrintable(t *testing.T) {
+	var tests = []struct {
+		Pattern   string
+		Printable bool
+	}{
+		{`[\x00-\x7f]{20}`, true},
+		{`[^a-z]{20}`, true},
+		{`(?s:.{20})`, true},
+		{`[\x00-\x1f]{4}`, false},
+		{`ab\x07c`, false},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithPrintable(true), WithNewlineProbability(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if got := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }) == -1; got != test.Printable {
+				t.Fatalf("%s: %q printable = %v, want %v", test.Pattern, s, got, test.Printable)
+			}
+		}
+	}
+}
+
+func TestWithPrintableDealt(t *testing.T) {
+	for _, opt := range []Option{WithMaximizeVariety(true), WithDistinctAlternatesInRepeat(true)} {
+		iRe, err := NewInverseRegex(`[\x00-\x7f]{40}`, WithSeed(1), WithPrintable(true), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }) != -1 {
+				t.Fatalf("%q is not printable", s)
+			}
+		}
+	}
+}
+
+func TestWithPrintableStrict(t *testing.T) {
+	for _, pattern := range []string{`[a-z]+`, `[\x00-\x7f]{20}`, `(?s:.)`} {
+		if _, err := NewInverseRegex(pattern, WithPrintableStrict(true)); err != nil {
+			t.Errorf("%s: unexpected error %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{`[\x00-\x1f]{4}`, `ab\x07c`, `x(?:\t)?`} {
+		if _, err := NewInverseRegex(pattern, WithPrintableStrict(true)); !errors.Is(err, ErrUnprintable) {
+			t.Errorf("%s: got error %v, want ErrUnprintable", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package xeger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Record is a generated value together with what is needed to reproduce
+// it: compiling Pattern with WithSeed(Seed) and the same other options and
+// calling Generate once yields Value again.
+type Record struct {
+	Pattern string `json:"pattern"`
+	Seed    int64  `json:"seed"`
+	Value   string `json:"value"`
+}
+
+// Pattern returns the regular expression x was built from, as passed to
+// NewInverseRegex.
+func (x *Xeger) Pattern() string {
+	return x.pattern
+}
+
+// GenerateRecord generates a value from a fresh seed drawn from the instance
+// RNG and returns it as a Record. Value is empty if generation fails.
+func (x *Xeger) GenerateRecord() Record {
+	x.mu.Lock()
+	seed := x.rng.Int63()
+	x.mu.Unlock()
+	s, _ := x.generate(rand.New(rand.NewSource(seed)))
+	return Record{Pattern: x.pattern, Seed: seed, Value: s}
+}
+
+// GenerateWithID is like Generate but also returns a stable id for the
+// value, for storing and referencing generated cases. The id is the
+// lowercase hex SHA-256 of the pattern, a NUL byte, and the value, so the
+// same value from two patterns gets two ids, while the same value from the
+// same pattern always gets the same id, whatever the seed. On failure it
+// returns two empty strings.
+func (x *Xeger) GenerateWithID() (value string, id string) {
+	s, err := x.Generate()
+	if err != nil {
+		return "", ""
+	}
+	return s, valueID(x.pattern, s)
+}
+
+// valueID returns the id GenerateWithID gives value from pattern.
+func valueID(pattern, value string) string {
+	h := sha256.New()
+	h.Write([]byte(pattern))
+	h.Write([]byte{0})
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GenerateStruct fills a record of fields, each with its own pattern, such
+// as {"id": `[0-9]{6}`, "email": `[a-z]+@example\.com`}. It builds a Xeger
+// for each field with opts and returns a valid match for every one, as
+// GenerateValid checks it. Fields are generated in sorted order, and the
+// first that fails to compile or generate is reported in the error.
+func GenerateStruct(patterns map[string]string, opts ...Option) (map[string]string, error) {
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make(map[string]string, len(patterns))
+	for _, name := range names {
+		x, err := NewInverseRegex(patterns[name], opts...)
+		if err != nil {
+			return nil, fmt.Errorf("xeger: field %q: %w", name, err)
+		}
+		s, err := x.GenerateValid()
+		if err != nil {
+			return nil, fmt.Errorf("xeger: field %q: %w", name, err)
+		}
+		out[name] = s
+	}
+	return out, nil
+}
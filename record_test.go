@@ -0,0 +1,114 @@
+package xeger
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecord(t *testing.T) {
+	const pattern = `[a-z]{3,8}@example\.(com|org)`
+	iRe, err := NewInverseRegex(pattern, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := iRe.Pattern(); got != pattern {
+		t.Errorf("Pattern() = %q, want %q", got, pattern)
+	}
+	for i := 0; i < 10; i++ {
+		rec := iRe.GenerateRecord()
+		if rec.Pattern != pattern || !iRe.regexp.MatchString(rec.Value) {
+			t.Fatalf("bad record %+v", rec)
+		}
+
+		b, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Record
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		if back != rec {
+			t.Fatalf("round trip of %s gave %+v", b, back)
+		}
+
+		replay, err := NewInverseRegex(back.Pattern, WithSeed(back.Seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := replay.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != rec.Value {
+			t.Errorf("replaying seed %d gave %q, want %q", rec.Seed, s, rec.Value)
+		}
+	}
+}
+
+func TestGenerateWithID(t *testing.T) {
+	iRe, err := NewInverseRegex(`[ab]{2}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		v, id := iRe.GenerateWithID()
+		if len(id) != 64 {
+			t.Fatalf("%q: id %q is not a hex SHA-256", v, id)
+		}
+		if prev, ok := ids[v]; ok && prev != id {
+			t.Fatalf("%q got ids %s and %s", v, prev, id)
+		}
+		ids[v] = id
+	}
+	if len(ids) != 4 {
+		t.Errorf("got %d distinct values, want 4", len(ids))
+	}
+
+	// the same value from another pattern gets another id
+	other, err := NewInverseRegex(`aa`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, id := other.GenerateWithID()
+	if id == ids["aa"] {
+		t.Errorf("aa got the same id from two patterns")
+	}
+	// the documented algorithm: the SHA-256 of "aa\x00aa"
+	if want := "70e7e25190c48b1a8589e483db3ea65749c6c9d10c5d57257d6c1ba9cbe78edc"; id != want {
+		t.Errorf("got id %s, want %s", id, want)
+	}
+}
+
+func TestGenerateStruct(t *testing.T) {
+	patterns := map[string]string{
+		"id":    `[0-9]{6}`,
+		"email": `[a-z]{3,8}@example\.com`,
+		"role":  `admin|user`,
+	}
+	got, err := GenerateStruct(patterns, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(patterns) {
+		t.Fatalf("got %v, want a value for each of %v", got, patterns)
+	}
+	for name, pattern := range patterns {
+		if !regexp.MustCompile(`^(?:` + pattern + `)$`).MatchString(got[name]) {
+			t.Errorf("%s: %q does not match %s", name, got[name], pattern)
+		}
+	}
+
+	_, err = GenerateStruct(map[string]string{"ok": `[a-z]+`, "bad": `[a-z`})
+	if err == nil || !strings.Contains(err.Error(), `"bad"`) {
+		t.Errorf("got error %v, want one naming the bad field", err)
+	}
+	_, err = GenerateStruct(map[string]string{"empty": `x[^\x00-\x{10FFFF}]`})
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+}
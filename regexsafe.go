@@ -0,0 +1,24 @@
+package xeger
+
+import "strings"
+
+// regexMeta are the characters regexp.QuoteMeta escapes.
+const regexMeta = `\.+*?()|[]{}^$`
+
+// WithRegexSafe makes generated strings safe to paste into another regular
+// expression as literal text. Char classes and . avoid the metacharacters
+// \.+*?()|[]{}^$ wherever they have other runes to choose from, so that
+// .{5} gives five ordinary characters, and any metacharacter the pattern
+// forces, such as the \. of [a-z]+\.com, is escaped in the output as
+// regexp.QuoteMeta does.
+func WithRegexSafe(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.regexSafe = enabled
+		return nil
+	}
+}
+
+// notRegexMeta reports whether r is not a regexp metacharacter.
+func notRegexMeta(r rune) bool {
+	return !strings.ContainsRune(regexMeta, r)
+}
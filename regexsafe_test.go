@@ -0,0 +1,91 @@
+package xeger
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithRegexSafe(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Escaped bool
+	}{
+		{`.{5}`, false},
+		{`[^a-z0-9]{8}`, false},
+		{`(?i)[a-z().]{6}`, false},
+		{`[a-z]+\.com`, true},
+		{`[*+]`, true},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithRegexSafe(true))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 50; i++ {
+			s, err := iRe.Generate()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			re, err := regexp.Compile(`^` + s + `$`)
+			if err != nil {
+				t.Fatalf("%s: %q is not safe in a pattern: %v", test.Pattern, s, err)
+			}
+			if test.Escaped != strings.Contains(s, `\`) {
+				t.Fatalf("%s: %q escaped = %v, want %v", test.Pattern, s, !test.Escaped, test.Escaped)
+			}
+			raw := strings.ReplaceAll(s, `\`, ``)
+			if !iRe.regexp.MatchString(raw) || !re.MatchString(raw) {
+				t.Fatalf("%s: %q does not unescape to a match", test.Pattern, s)
+			}
+		}
+	}
+}
+
+func TestWithRegexSafeDealt(t *testing.T) {
+	check := func(name, s string) {
+		t.Helper()
+		if strings.ContainsAny(s, `\.+*?()|[]{}^$`) {
+			t.Fatalf("%s: %q contains a metacharacter", name, s)
+		}
+	}
+	for _, opt := range []Option{WithMaximizeVariety(true), WithDistinctAlternatesInRepeat(true)} {
+		iRe, err := NewInverseRegex(`[!-/]{10}`, WithSeed(1), WithRegexSafe(true), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			s, err := iRe.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(`[!-/]{10}`, s)
+		}
+	}
+
+	iRe, err := NewInverseRegex(`[!-/]{10}`, WithSeed(1), WithRegexSafe(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range iRe.GenerateDiverse(10) {
+		check("GenerateDiverse", s)
+	}
+}
+
+func TestWithRegexSafeReplay(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{2,4}\.[a-z]{2}`, WithSeed(1), WithRegexSafe(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, decisions := iRe.GenerateWithDecisions()
+		got, err := iRe.ReplayDecisions(decisions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != s || !strings.Contains(got, `\.`) {
+			t.Fatalf("replay gave %q, want %q", got, s)
+		}
+	}
+}
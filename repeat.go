@@ -0,0 +1,283 @@
+package xeger
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"slices"
+	"unicode/utf8"
+)
+
+// defaultMaxReps is how far an unbounded quantifier repeats beyond its
+// minimum unless configured otherwise.
+const defaultMaxReps = 10
+
+// A RepStrategy picks how many times a quantifier repeats, returning a
+// count in [min, max]. Unbounded quantifiers have already had max capped
+// by the configured maximum repetitions.
+type RepStrategy func(rng *rand.Rand, min, max int) int
+
+// UniformReps is a RepStrategy choosing every count in range with equal
+// probability.
+func UniformReps(rng *rand.Rand, min, max int) int {
+	return min + rng.Intn(max-min+1)
+}
+
+// GeometricReps is a RepStrategy favouring small counts: starting from min,
+// each further repetition happens with probability 1/2, so min is chosen
+// half the time and the mean is about one repetition over it. Counts are
+// truncated at max.
+func GeometricReps(rng *rand.Rand, min, max int) int {
+	n := min
+	for n < max && rng.Intn(2) == 0 {
+		n++
+	}
+	return n
+}
+
+// WithMaxReps sets how many extra repetitions beyond its minimum an
+// unbounded quantifier may generate: with n of 5, a{2,} yields between 2
+// and 7 copies and a* between 0 and 5. It also caps the count of a bounded
+// quantifier at n, without going below its minimum, so that a{1,1000000}
+// yields between 1 and 5 copies while a{8,9} still yields 8.
+func WithMaxReps(n int) Option {
+	return func(x *Xeger) error {
+		if n < 0 {
+			return fmt.Errorf("xeger: max reps must not be negative, got %d", n)
+		}
+		x.maxReps = n
+		return nil
+	}
+}
+
+// WithRepStrategy sets how quantifiers choose their repeat counts. By
+// default, or with a nil strategy, unbounded quantifiers such as * and +
+// use GeometricReps so that typical output stays short even with a large
+// WithMaxReps, while bounded ones such as {2,5} use UniformReps. A non-nil
+// strategy is used for every quantifier.
+func WithRepStrategy(s RepStrategy) Option {
+	return func(x *Xeger) error {
+		x.repStrategy = s
+		return nil
+	}
+}
+
+// WithExactRepeat pins the quantifier at site to count repetitions,
+// whatever its bounds would otherwise allow, for building specific boundary
+// cases. Sites number the quantifiers *, +, ? and {n,m} of the pattern in
+// the order they are written, so in [a-z]+@[a-z]+(\.com)? the second + is
+// site 1 and the ? site 2. The count is used as given even beyond the
+// configured maximum repetitions. It is an error if there is no such site
+// or count is outside the quantifier's bounds.
+func WithExactRepeat(site, count int) Option {
+	return func(x *Xeger) error {
+		var quants []*syntax.Regexp
+		quants = quantifiers(x.re, quants)
+		if site < 0 || site >= len(quants) {
+			return fmt.Errorf("xeger: no quantifier site %d in %s, which has %d", site, x.re, len(quants))
+		}
+		re := quants[site]
+		if min, max := countBounds(re); count < min || (max != -1 && count > max) {
+			return fmt.Errorf("xeger: count %d is outside the bounds of %s", count, re)
+		}
+		if x.exactRepeats == nil {
+			x.exactRepeats = make(map[*syntax.Regexp]int)
+		}
+		x.exactRepeats[re] = count
+		return nil
+	}
+}
+
+// quantifiers appends the quantifiers of re to quants in preorder.
+func quantifiers(re *syntax.Regexp, quants []*syntax.Regexp) []*syntax.Regexp {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		quants = append(quants, re)
+	}
+	for _, sub := range re.Sub {
+		quants = quantifiers(sub, quants)
+	}
+	return quants
+}
+
+// countBounds returns the least and most times the quantifier re
+// repeats, with a max of -1 meaning unbounded.
+func countBounds(re *syntax.Regexp) (min, max int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	return re.Min, re.Max
+}
+
+// reps decides how many times the quantifier re repeats.
+func (g *generator) reps(re *syntax.Regexp) (int, error) {
+	min, max := countBounds(re)
+	return g.decide(DecisionRepeat, min,
+		func() int {
+			if n, ok := g.x.exactRepeats[re]; ok {
+				return n
+			}
+			if g.x.tooDeep[re] {
+				return min
+			}
+			lo := min
+			if g.x.allOptionals && min == 0 && max != 0 {
+				lo = 1
+			}
+			if g.steer != nil {
+				if n, ok := g.steerCount(re, lo, max); ok {
+					return n
+				}
+			}
+			if g.visits != nil && lo == 0 && max != 0 && g.uncovered(re.Sub[0]) {
+				return 1
+			}
+			if g.variety != nil {
+				return g.dealCount(g.variety, re, lo, max)
+			}
+			return g.drawCount(re, lo, max)
+		},
+		func(n int) bool { return n >= min && (max == -1 || n <= max) })
+}
+
+// drawCount chooses a count for the quantifier re, repeating between min
+// and max times.
+func (g *generator) drawCount(re *syntax.Regexp, min, max int) int {
+	if re.Op == syntax.OpQuest && min == 0 {
+		if g.rng.Float64() < g.x.questProb*(1-g.x.compact) {
+			return 1
+		}
+		return 0
+	}
+	return g.repeatCount(min, max)
+}
+
+// repeatCount chooses a count for a quantifier repeating between min and
+// max times, where a max of -1 means unbounded.
+func (g *generator) repeatCount(min, max int) int {
+	strategy := g.repStrategy
+	if max == -1 && strategy == nil {
+		strategy = GeometricReps
+	}
+	max = g.countLimit(min, max)
+	if max == min {
+		return min
+	}
+	if strategy == nil {
+		strategy = UniformReps
+	}
+	return strategy(g.rng, min, max)
+}
+
+// countLimit returns the largest count repeatCount may choose for a
+// quantifier repeating between min and max times.
+func (g *generator) countLimit(min, max int) int {
+	switch {
+	case max == -1:
+		max = min + g.maxReps
+	case max > g.maxReps:
+		max = g.maxReps
+	}
+	if max <= min {
+		return min
+	}
+	if c := g.x.compact; c > 0 {
+		max = min + int(float64(max-min)*(1-c))
+	}
+	return max
+}
+
+// GenerateWithMaxReps is like Generate but caps unbounded quantifiers at n
+// extra repetitions for this call only, leaving the configured WithMaxReps
+// in place for later calls.
+func (x *Xeger) GenerateWithMaxReps(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("xeger: max reps must not be negative, got %d", n)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.maxReps = n
+	return g.generate()
+}
+
+// GenerateGrowing returns progressively longer strings on successive calls,
+// for feeding a consumer a ramp of input sizes. The k'th call since the
+// last reset repeats every quantifier as often as it may with k in place
+// of the configured maximum repetitions, so a+ gives a, aa, aaa and so on,
+// and [0-9]{2,5} gives 2, 2, 2, 3, 4, 5 digits. Optional parts and
+// branches are chosen as usual, so lengths grow only where quantifiers
+// decide them. After the call at the configured maximum the schedule
+// starts over from zero. On failure it returns an empty string.
+func (x *Xeger) GenerateGrowing() string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.maxReps = x.growLevel
+	g.repStrategy = mostReps
+	x.growLevel++
+	if x.growLevel > x.maxReps {
+		x.growLevel = 0
+	}
+	s, err := g.generate()
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// ResetGrowing starts the schedule of GenerateGrowing over, so its next
+// call gives the shortest strings again.
+func (x *Xeger) ResetGrowing() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.growLevel = 0
+}
+
+// mostReps is a RepStrategy always choosing max.
+func mostReps(_ *rand.Rand, _, max int) int {
+	return max
+}
+
+// plainLiteral reports whether re is a literal written out as is, with no
+// random decisions to make.
+func plainLiteral(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase == 0
+}
+
+// appendLiteral appends count copies of the literal runes, encoding them
+// once and then doubling the copied run, as bytes.Repeat does.
+func (g *generator) appendLiteral(runes []rune, count int) {
+	if count <= 0 {
+		return
+	}
+	start := len(g.buf)
+	for _, r := range runes {
+		g.buf = utf8.AppendRune(g.buf, r)
+	}
+	total := (len(g.buf) - start) * count
+	g.buf = slices.Grow(g.buf, total-(len(g.buf)-start))
+	for done := len(g.buf) - start; done < total; {
+		n := min(done, total-done)
+		g.buf = append(g.buf, g.buf[start:start+n]...)
+		done += n
+	}
+}
+
+// literalRun notes the output of run, copies of one plain literal emitted
+// from offset start of the buffer without going through makeMatch.
+func (g *generator) literalRun(run []*syntax.Regexp, start int) {
+	if g.leafSpans {
+		g.leafSpan(run[0], start)
+	}
+	if g.visits != nil {
+		for _, re := range run {
+			g.visits[re] = true
+		}
+	}
+}
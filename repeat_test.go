@@ -0,0 +1,341 @@
+package xeger
+
+import (
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUnboundedRepeat(t *testing.T) {
+	iRe, err := NewInverseRegex(`a{2,}`, WithSeed(1), WithMaxReps(5), WithRepStrategy(UniformReps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[int]int)
+	for i := 0; i < 500; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[len(s)]++
+	}
+	for n := range counts {
+		if n < 2 || n > 7 {
+			t.Errorf("generated %d copies, want between 2 and 7", n)
+		}
+	}
+	if len(counts) != 6 {
+		t.Errorf("expected all counts from 2 to 7, got %v", counts)
+	}
+}
+
+func TestQuantifiers(t *testing.T) {
+	var tests = []string{
+		`a*`,
+		`a+`,
+		`a?`,
+		`(ab)*c`,
+		`x[0-9]+y`,
+		`(foo|bar)?baz`,
+	}
+
+	for _, pattern := range tests {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		seen := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", pattern, err)
+			}
+			seen[s] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("%s: expected varied output, got %v", pattern, seen)
+		}
+	}
+}
+
+func TestWithRepStrategy(t *testing.T) {
+	max := func(rng *rand.Rand, min, max int) int { return max }
+	iRe, err := NewInverseRegex(`a{2,}b{1,3}`, WithMaxReps(4), WithRepStrategy(max))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := iRe.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Repeat("a", 6) + "bbb"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+}
+
+func TestDefaultRepsFavourSmallCounts(t *testing.T) {
+	iRe, err := NewInverseRegex(`a+`, WithSeed(1), WithMaxReps(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total, ones int
+	for i := 0; i < 1000; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += len(s)
+		if len(s) == 1 {
+			ones++
+		}
+	}
+	if mean := float64(total) / 1000; mean > 3 {
+		t.Errorf("mean length %.2f, want short strings by default", mean)
+	}
+	if ones < 400 {
+		t.Errorf("only %d of 1000 strings had the minimum count", ones)
+	}
+}
+
+func TestDefaultRepsUniformWhenBounded(t *testing.T) {
+	iRe, err := NewInverseRegex(`a{1,4}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[len(s)]++
+	}
+	for n := 1; n <= 4; n++ {
+		if counts[n] < 200 {
+			t.Errorf("count %d chosen %d times in 1000, want about 250", n, counts[n])
+		}
+	}
+}
+
+func TestGenerateWithMaxReps(t *testing.T) {
+	all := func(rng *rand.Rand, min, max int) int { return max }
+	iRe, err := NewInverseRegex(`a*`, WithMaxReps(2), WithRepStrategy(all))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := iRe.GenerateWithMaxReps(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 50 {
+		t.Errorf("override gave %d reps, want 50", len(s))
+	}
+	s, err = iRe.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Errorf("after override got %d reps, want the configured 2", len(s))
+	}
+}
+
+// The parser caps counts at 1000, so longer literal runs are built from
+// several repeats.
+func BenchmarkLongLiteralRepeat(b *testing.B) {
+	for _, simplify := range []bool{false, true} {
+		name := "Repeat"
+		if simplify {
+			name = "Simplified"
+		}
+		b.Run(name, func(b *testing.B) {
+			iRe, err := NewInverseRegex(`a{1000}b{1000}`, WithSeed(1))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if simplify {
+				iRe.re = iRe.re.Simplify()
+			}
+			buf := make([]byte, 0, 2000)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if buf, err = iRe.AppendTo(buf[:0]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLiteralRuns(t *testing.T) {
+	var tests = []struct {
+		Pattern  string
+		Simplify bool
+		Want     string
+	}{
+		{`a{5}b{3}`, false, "aaaaabbb"},
+		{`a{5}b{3}`, true, "aaaaabbb"},
+		{`(?:ab){3}x{0}c`, true, "abababc"},
+		{`é{4}`, true, "éééé"},
+		{`(?i:k){3}`, true, "kkk"},
+		{`a{1000}`, true, strings.Repeat("a", 1000)},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if test.Simplify {
+			iRe.re = iRe.re.Simplify()
+		}
+		got, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatalf("%s: %v", test.Pattern, err)
+		}
+		if got != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+}
+
+func TestBoundedRepeatCap(t *testing.T) {
+	var tests = []struct {
+		Pattern  string
+		Opts     []Option
+		Min, Max int
+	}{
+		{`a{1,1000}`, []Option{WithMaxReps(5)}, 1, 5},
+		{`(?:a{1,30}){1,30}`, []Option{WithMaxReps(3)}, 1, 9},
+		{`a{1,1000}`, nil, 1, defaultMaxReps},
+		{`a{8,9}`, []Option{WithMaxReps(2)}, 8, 8},
+		{`a{2,4}`, []Option{WithMaxReps(100)}, 2, 4},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		counts := make(map[int]bool)
+		for i := 0; i < 300; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if len(s) < test.Min || len(s) > test.Max {
+				t.Fatalf("%s: got %d copies, want between %d and %d", test.Pattern, len(s), test.Min, test.Max)
+			}
+			counts[len(s)] = true
+		}
+		if !counts[test.Min] || !counts[test.Max] {
+			t.Errorf("%s: never reached both ends of [%d, %d]: %v", test.Pattern, test.Min, test.Max, counts)
+		}
+	}
+}
+
+func TestSimplifiedRepeatOfConcat(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Min     int
+	}{
+		{`(ab)+`, 1},
+		{`(ab)*`, 0},
+		{`(?:ab)+c`, 1},
+		{`(ab){2,4}`, 2},
+		{`(?:a[0-9]){3}`, 3},
+	}
+
+	for _, test := range tests {
+		re, err := syntax.Parse(test.Pattern, syntax.Perl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		re = re.Simplify()
+		iRe := NewFromSyntax(re, WithSeed(1), WithMaxReps(4))
+		if iRe == nil {
+			t.Fatalf("%s: got nil", test.Pattern)
+		}
+		unit := regexp.MustCompile(`ab|a[0-9]`)
+		counts := make(map[int]bool)
+		for i := 0; i < 100; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			n := len(unit.FindAllString(s, -1))
+			if n < test.Min {
+				t.Fatalf("%s: %q has %d copies, want at least %d", test.Pattern, s, n, test.Min)
+			}
+			counts[n] = true
+		}
+		if strings.ContainsAny(test.Pattern, "+*,") && len(counts) < 2 {
+			t.Errorf("%s: every string had the same number of copies", test.Pattern)
+		}
+	}
+}
+
+func TestWithExactRepeat(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Site    int
+		Count   int
+		Want    string
+	}{
+		{`[a-z]+@[a-z]+(\.com)?`, 0, 5, `^[a-z]{5}@[a-z]+(\.com)?$`},
+		{`[a-z]+@[a-z]+(\.com)?`, 1, 12, `^[a-z]+@[a-z]{12}(\.com)?$`},
+		{`[a-z]+@[a-z]+(\.com)?`, 2, 0, `^[a-z]+@[a-z]+$`},
+		{`(?:x[0-9]{1,3}){2,4}`, 0, 3, `^(?:x[0-9]{1,3}){3}$`},
+		{`(?:x[0-9]{1,3}){2,4}`, 1, 3, `^(?:x[0-9]{3}){2,4}$`},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithExactRepeat(test.Site, test.Count))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		want := regexp.MustCompile(test.Want)
+		for i := 0; i < 20; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if !want.MatchString(s) {
+				t.Fatalf("%s: site %d pinned to %d gave %q", test.Pattern, test.Site, test.Count, s)
+			}
+		}
+	}
+
+	for _, bad := range [][2]int{{3, 1}, {-1, 1}, {0, 0}, {2, 2}} {
+		if _, err := NewInverseRegex(`[a-z]+@[a-z]+(\.com)?`, WithExactRepeat(bad[0], bad[1])); err == nil {
+			t.Errorf("site %d, count %d: expected an error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestGenerateGrowing(t *testing.T) {
+	iRe, err := NewInverseRegex(`x(?:[0-9]{2,5}-)+`, WithSeed(1), WithMaxReps(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lengths []int
+	for i := 0; i < 7; i++ {
+		s := iRe.GenerateGrowing()
+		if !iRe.regexp.MatchString(s) {
+			t.Fatalf("%q does not match", s)
+		}
+		lengths = append(lengths, len(s))
+	}
+	// levels 0 to 4, then over from 0
+	want := []int{4, 7, 10, 17, 26, 4, 7}
+	if !slices.Equal(lengths, want) {
+		t.Errorf("got lengths %v, want %v", lengths, want)
+	}
+
+	iRe.ResetGrowing()
+	if s := iRe.GenerateGrowing(); len(s) != 4 {
+		t.Errorf("after ResetGrowing got %q, want the shortest string", s)
+	}
+}
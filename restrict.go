@@ -0,0 +1,48 @@
+package xeger
+
+// allowAll returns a function accepting the runes every one of allow
+// accepts, or nil if allow is empty.
+func allowAll(allow []func(rune) bool) func(rune) bool {
+	switch len(allow) {
+	case 0:
+		return nil
+	case 1:
+		return allow[0]
+	}
+	return func(r rune) bool {
+		for _, f := range allow {
+			if !f(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// pickAllowed returns a rune from ranges, with cumulative sizes as for
+// drawRune, accepted by g.allow, given that the first draw, r, was
+// rejected. It redraws a few times, then falls back to scanning the start
+// of the class, which finds the allowed runes of small alphabets even when
+// they are a sliver of a large class. If no allowed rune turns up, r is
+// returned.
+func (g *generator) pickAllowed(ranges []rune, sizes []int64, r rune) rune {
+	for i := 0; i < maxDealAttempts; i++ {
+		if c := g.drawRune(ranges, sizes); g.allow(c) {
+			return c
+		}
+	}
+	var allowed []rune
+	scanned := 0
+	for i := 0; i < len(ranges) && scanned < maxScannedClass; i += 2 {
+		for c := ranges[i]; c <= ranges[i+1] && scanned < maxScannedClass; c++ {
+			if g.allow(c) {
+				allowed = append(allowed, c)
+			}
+			scanned++
+		}
+	}
+	if len(allowed) == 0 {
+		return r
+	}
+	return allowed[g.rng.Intn(len(allowed))]
+}
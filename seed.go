@@ -0,0 +1,66 @@
+package xeger
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// GenerateAt returns the index'th string of the sequence determined by the
+// base seed. The result depends only on the seed and index, not on any
+// prior calls, so it is safe to call concurrently.
+func (x *Xeger) GenerateAt(index int) (string, error) {
+	return x.generate(rand.New(rand.NewSource(subSeed(x.seed, uint64(index)+1))))
+}
+
+// GenerateForKey returns the string the base seed associates with key, so
+// that a logical test case such as "user-email-1" gets the same value on
+// every run. The seed for key is its 64-bit FNV-1a hash mixed with the base
+// seed by the splitmix64 finalizer; both are stable across releases. An
+// empty string is returned if generation fails.
+func (x *Xeger) GenerateForKey(key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	s, _ := x.generate(rand.New(rand.NewSource(subSeed(x.seed, h.Sum64()))))
+	return s
+}
+
+// WithSeedFromPattern seeds the random source from the pattern itself when
+// no WithSeed is given, so that the same pattern always generates the same
+// sequence while different patterns differ, such as for documentation
+// examples that must stay stable. The seed is the 64-bit FNV-1a hash of the
+// pattern text mixed as for GenerateForKey, so equivalent patterns spelled
+// differently, like [ab] and a|b, get different seeds.
+func WithSeedFromPattern(enabled bool) Option {
+	return func(x *Xeger) error {
+		x.seedFromPattern = enabled
+		return nil
+	}
+}
+
+// patternSeed returns the seed WithSeedFromPattern gives pattern.
+func patternSeed(pattern string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(pattern))
+	return subSeed(0, h.Sum64())
+}
+
+// subSeed mixes seed and n with the splitmix64 finalizer so that
+// neighbouring values of n yield unrelated random streams.
+func subSeed(seed int64, n uint64) int64 {
+	z := uint64(seed) + n*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// GenerateMatrix returns one string per seed, each generated from a fresh
+// random source with that seed, so that element i is what a Xeger built
+// with WithSeed(seeds[i]) would produce first. The instance's own sequence
+// is not disturbed. Elements whose generation fails are left empty.
+func (x *Xeger) GenerateMatrix(seeds []int64) []string {
+	out := make([]string, len(seeds))
+	for i, seed := range seeds {
+		out[i], _ = x.generate(rand.New(rand.NewSource(seed)))
+	}
+	return out
+}
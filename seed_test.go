@@ -0,0 +1,116 @@
+package xeger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubSeedDistinct(t *testing.T) {
+	seen := make(map[int64]uint64)
+	for i := uint64(0); i < 1000; i++ {
+		s := subSeed(1, i)
+		if j, ok := seen[s]; ok {
+			t.Fatalf("values %d and %d share sub-seed %d", j, i, s)
+		}
+		seen[s] = i
+	}
+}
+
+func TestGenerateForKey(t *testing.T) {
+	a, err := NewInverseRegex(`[a-z][a-z][a-z][a-z][a-z][a-z]`, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewInverseRegex(`[a-z][a-z][a-z][a-z][a-z][a-z]`, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// advancing one instance's own sequence must not affect keyed output
+	if _, err := b.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"user-email-1", "user-email-2", ""} {
+		if ka, kb := a.GenerateForKey(key), b.GenerateForKey(key); ka != kb {
+			t.Errorf("key %q: got %q and %q from identically seeded generators", key, ka, kb)
+		}
+	}
+	if a.GenerateForKey("user-email-1") == a.GenerateForKey("user-email-2") {
+		t.Errorf("expected distinct keys to give distinct values")
+	}
+}
+
+func TestGenerateMatrix(t *testing.T) {
+	const pattern = `[a-z]{3,8}[0-9]?`
+	iRe, err := NewInverseRegex(pattern, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := NewInverseRegex(pattern, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeds := []int64{0, 1, 2, 42, -7}
+	got := iRe.GenerateMatrix(seeds)
+	if len(got) != len(seeds) {
+		t.Fatalf("got %d results, want %d", len(got), len(seeds))
+	}
+	for i, seed := range seeds {
+		fresh, err := NewInverseRegex(pattern, WithSeed(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := fresh.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[i] != want {
+			t.Errorf("seed %d: got %q, want %q", seed, got[i], want)
+		}
+	}
+	if again := iRe.GenerateMatrix(seeds); strings.Join(again, ",") != strings.Join(got, ",") {
+		t.Errorf("second matrix %q differs from first %q", again, got)
+	}
+
+	// the instance's own sequence is undisturbed
+	a, _ := iRe.Generate()
+	b, _ := ref.Generate()
+	if a != b {
+		t.Errorf("GenerateMatrix disturbed the sequence: got %q, want %q", a, b)
+	}
+}
+
+func TestWithSeedFromPattern(t *testing.T) {
+	generate := func(pattern string, opts ...Option) []string {
+		iRe, err := NewInverseRegex(pattern, append([]Option{WithSeedFromPattern(true)}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := iRe.GenerateN(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	const pattern = `[a-z]{8}-[0-9]{4}`
+	a, b := generate(pattern), generate(pattern)
+	if strings.Join(a, " ") != strings.Join(b, " ") {
+		t.Errorf("same pattern gave %q then %q", a, b)
+	}
+	if c := generate(`[a-z]{8}-[0-9]{4}x?`); strings.TrimSuffix(c[0], "x") == a[0] {
+		t.Errorf("different patterns both began with %q", a[0])
+	}
+
+	// an explicit seed wins, in either order
+	want := generate(pattern, WithSeed(3))
+	iRe, err := NewInverseRegex(pattern, WithSeed(3), WithSeedFromPattern(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := iRe.GenerateN(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") || strings.Join(got, " ") == strings.Join(a, " ") {
+		t.Errorf("WithSeed(3) gave %q and %q, pattern seed %q", got, want, a)
+	}
+}
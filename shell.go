@@ -0,0 +1,63 @@
+package xeger
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A ShellQuoting is a way of quoting generated output for a POSIX shell
+// with WithShellSafe.
+type ShellQuoting int
+
+const (
+	// ShellSingleQuote wraps output in single quotes, within which the
+	// shell expands nothing, writing each ' as '\''.
+	ShellSingleQuote ShellQuoting = iota + 1
+	// ShellDoubleQuote wraps output in double quotes, escaping each of
+	// \ " $ and ` with a backslash.
+	ShellDoubleQuote
+)
+
+// shellMeta are the ASCII characters a POSIX shell treats specially
+// outside quotes.
+const shellMeta = " \t\n!\"#$&'()*;<=>?[\\]`{|}~"
+
+// WithShellSafe makes generated strings ready to embed in a shell command
+// line, such as for fuzzing a CLI's arguments. Char classes and . avoid
+// the characters !"#$&'()*;<=>?[\]`{|}~, whitespace and other unprintable
+// runes wherever they have other runes to choose from, and every string is
+// then quoted as q says, so any such character the pattern forces is
+// escaped. Double quotes cannot protect a ! from history expansion in an
+// interactive bash, so prefer ShellSingleQuote where a pattern forces one.
+func WithShellSafe(q ShellQuoting) Option {
+	return func(x *Xeger) error {
+		switch q {
+		case ShellSingleQuote:
+			x.shellQuote = singleQuote
+		case ShellDoubleQuote:
+			x.shellQuote = doubleQuote
+		default:
+			return fmt.Errorf("xeger: unknown shell quoting %d", q)
+		}
+		return nil
+	}
+}
+
+// notShellMeta reports whether r is printable and not special to a shell.
+func notShellMeta(r rune) bool {
+	return unicode.IsPrint(r) && !strings.ContainsRune(shellMeta, r)
+}
+
+// singleQuote returns s single-quoted for a shell.
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// doubleQuoter escapes the characters special within double quotes.
+var doubleQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+
+// doubleQuote returns s double-quoted for a shell.
+func doubleQuote(s string) string {
+	return `"` + doubleQuoter.Replace(s) + `"`
+}
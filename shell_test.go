@@ -0,0 +1,69 @@
+package xeger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithShellSafe(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Quoting ShellQuoting
+		Want    string
+	}{
+		{`it's \$5`, ShellSingleQuote, `'it'\''s $5'`},
+		{`it's \$5`, ShellDoubleQuote, `"it's \$5"`},
+		{"say \"hi\" \\\\ `id`", ShellDoubleQuote, "\"say \\\"hi\\\" \\\\ \\`id\\`\""},
+		{``, ShellSingleQuote, `''`},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithShellSafe(test.Quoting))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		s, err := iRe.Generate()
+		if err != nil {
+			t.Fatalf("%s: %v", test.Pattern, err)
+		}
+		if s != test.Want {
+			t.Errorf("%s: got %s, want %s", test.Pattern, s, test.Want)
+		}
+	}
+
+	// random picks stay clear of shell metacharacters
+	iRe, err := NewInverseRegex(`[^a-z]{20}|.{20}`, WithSeed(1), WithShellSafe(ShellSingleQuote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		s, err := iRe.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := s[1 : len(s)-1]; strings.ContainsAny(body, shellMeta) {
+			t.Fatalf("%s contains a shell metacharacter", s)
+		}
+	}
+
+	if _, err := NewInverseRegex(`a`, WithShellSafe(0)); err == nil {
+		t.Error("expected an error for an unknown quoting")
+	}
+}
+
+func TestWithShellSafeReplay(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{3,5}/x`, WithSeed(1), WithShellSafe(ShellSingleQuote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, decisions := iRe.GenerateWithDecisions()
+		got, err := iRe.ReplayDecisions(decisions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != s || !strings.HasPrefix(got, "'") {
+			t.Fatalf("replay gave %s, want %s", got, s)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package xeger
+
+import "regexp/syntax"
+
+// A Span locates the output of one top-level part of a pattern within a
+// generated string.
+type Span struct {
+	// Op and Fragment are the operation and source text of the part, as
+	// reformatted by the parser.
+	Op       syntax.Op
+	Fragment string
+
+	// Start and End are the byte offsets of the part's output.
+	Start int
+	End   int
+
+	// Flags are the parser flags of the part, such as FoldCase inside
+	// (?i:...) or OneLine outside (?m:...). For a part made of others
+	// they are only those in effect where it starts; GenerateWithFlagSpans
+	// reports them for each leaf.
+	Flags syntax.Flags
+}
+
+// GenerateWithSpans is like Generate but also reports which part of the
+// output came from which part of the pattern: one Span per element of a
+// top-level concatenation, such as the [a-z]+, @ and [a-z]+\.com of
+// [a-z]+@[a-z]+\.com, or a single Span for a pattern that is not one.
+// Unlike capture tracking it covers every part, captured or not. With
+// surrounding noise the spans locate the parts within the noise. On
+// failure it returns an empty string and nil.
+func (x *Xeger) GenerateWithSpans() (string, []Span) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.recordSpans = true
+	s, err := g.generate()
+	if err != nil {
+		return "", nil
+	}
+	return s, g.spans
+}
+
+// GenerateWithFlagSpans is like GenerateWithSpans but reports a Span for
+// every leaf of the pattern that was generated, such as each literal, char
+// class and assertion, in output order. Its Flags show which flags applied
+// to that piece of output, so that for ab(?i:cd)(?m:$) the spans say cd was
+// case folded and the $ matched at a line end. Each repetition of a
+// char class is a span of its own, while a literal repeated as in a{3} is
+// a single span. On failure it returns an empty string and nil.
+func (x *Xeger) GenerateWithFlagSpans() (string, []Span) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.leafSpans = true
+	s, err := g.generate()
+	if err != nil {
+		return "", nil
+	}
+	return s, g.spans
+}
+
+// spanMatch generates re, recording a span for each of its top-level parts.
+func (g *generator) spanMatch(re *syntax.Regexp) error {
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+		// the concatenation is a level of the walk in its own right
+		g.depth++
+		defer func() { g.depth-- }()
+	}
+	for _, part := range parts {
+		start := len(g.buf)
+		if err := g.makeMatch(part); err != nil {
+			return err
+		}
+		g.spans = append(g.spans, Span{
+			Op:       part.Op,
+			Fragment: part.String(),
+			Start:    start - g.matchStart,
+			End:      len(g.buf) - g.matchStart,
+			Flags:    part.Flags,
+		})
+	}
+	return nil
+}
+
+// leafSpan records a span for the leaf re, whose output starts at offset
+// start of the buffer.
+func (g *generator) leafSpan(re *syntax.Regexp, start int) {
+	g.spans = append(g.spans, Span{
+		Op:       re.Op,
+		Fragment: re.String(),
+		Start:    start - g.matchStart,
+		End:      len(g.buf) - g.matchStart,
+		Flags:    re.Flags,
+	})
+}
+
+// dropSpans discards the spans recorded from offset start of the buffer
+// onwards, for output that is being generated again.
+func (g *generator) dropSpans(start int) {
+	n := len(g.spans)
+	for n > 0 && g.spans[n-1].Start >= start-g.matchStart {
+		n--
+	}
+	g.spans = g.spans[:n]
+}
+
+// shiftSpans moves the recorded spans and class picks n bytes later, for
+// noise inserted before the match.
+func (g *generator) shiftSpans(n int) {
+	for i := range g.spans {
+		g.spans[i].Start += n
+		g.spans[i].End += n
+	}
+	for i := range g.classPicks {
+		g.classPicks[i].Offset += n
+	}
+}
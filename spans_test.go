@@ -0,0 +1,108 @@
+package xeger
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"testing"
+)
+
+func TestGenerateWithSpans(t *testing.T) {
+	var tests = []struct {
+		Pattern   string
+		Opts      []Option
+		Fragments []string
+	}{
+		{`[a-z]+@[a-z]{3,8}\.com`, nil, []string{`[a-z]+`, `@`, `[a-z]{3,8}`, `\.com`}},
+		{`(?P<id>[0-9]{4})-(ab|cd)`, nil, []string{`(?P<id>[0-9]{4})`, `-`, `(ab|cd)`}},
+		{`[0-9]+`, nil, []string{`[0-9]+`}},
+		{`x[0-9]{2}y`, []Option{WithSurroundingNoise(true)}, []string{`x`, `[0-9]{2}`, `y`}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, append(test.Opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 20; i++ {
+			s, spans := iRe.GenerateWithSpans()
+			if len(spans) != len(test.Fragments) {
+				t.Fatalf("%s: got %d spans %v, want %d", test.Pattern, len(spans), spans, len(test.Fragments))
+			}
+			for j, sp := range spans {
+				if sp.Fragment != test.Fragments[j] {
+					t.Errorf("%s: span %d is for %s, want %s", test.Pattern, j, sp.Fragment, test.Fragments[j])
+				}
+				if j > 0 && sp.Start != spans[j-1].End {
+					t.Errorf("%s: span %d starts at %d, after %d", test.Pattern, j, sp.Start, spans[j-1].End)
+				}
+				sub, err := regexp.Compile(`^(?:` + sp.Fragment + `)$`)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !sub.MatchString(s[sp.Start:sp.End]) {
+					t.Errorf("%s: %q at span %d does not match %s", test.Pattern, s[sp.Start:sp.End], j, sp.Fragment)
+				}
+			}
+			if whole := s[spans[0].Start:spans[len(spans)-1].End]; !iRe.regexp.MatchString(whole) {
+				t.Errorf("%s: spans of %q cover %q, not a match", test.Pattern, s, whole)
+			}
+		}
+	}
+}
+
+func TestGenerateWithFlagSpans(t *testing.T) {
+	iRe, err := NewInverseRegex(`ab(?i:cd)(?m:$)(?:x{3}|[0-9]{2})`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, spans := iRe.GenerateWithFlagSpans()
+		// one span for xxx, or one per digit of [0-9]{2}
+		if len(spans) != 4 && len(spans) != 5 {
+			t.Fatalf("%q: got spans %v, want 4 or 5", s, spans)
+		}
+		end := 0
+		for j, sp := range spans {
+			if sp.Start != end {
+				t.Errorf("%q: span %d starts at %d, after %d", s, j, sp.Start, end)
+			}
+			end = sp.End
+		}
+		if end != len(s) {
+			t.Errorf("%q: spans end at %d", s, end)
+		}
+		if spans[0].Flags&syntax.FoldCase != 0 || spans[1].Flags&syntax.FoldCase == 0 {
+			t.Errorf("%q: fold case flags %v then %v", s, spans[0].Flags, spans[1].Flags)
+		}
+		if spans[2].Op != syntax.OpEndLine || spans[2].Flags&syntax.OneLine != 0 || spans[0].Flags&syntax.OneLine == 0 {
+			t.Errorf("%q: expected a multiline $ span, got %v", s, spans[2])
+		}
+		if got := s[spans[3].Start:spans[3].End]; got != "xxx" && len(got) != 1 {
+			t.Errorf("%q: span 3 covers %q", s, got)
+		}
+	}
+}
+
+func TestGenerateWithFlagSpansCaptureRetry(t *testing.T) {
+	iRe, err := NewInverseRegex(`(?P<n>[a-z]+)`, WithSeed(1), WithCaptureLength("n", 3, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, spans := iRe.GenerateWithFlagSpans()
+	// the discarded attempts must leave no spans behind
+	if len(s) != 3 || len(spans) != 3 || spans[2].End != 3 {
+		t.Errorf("got %q with spans %v", s, spans)
+	}
+}
+
+func TestGenerateWithFlagSpansSkippedLiteral(t *testing.T) {
+	iRe, err := NewInverseRegex(`(a|b)(c|d)e?`, WithSeed(1), WithQuestProbability(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, spans := iRe.GenerateWithFlagSpans()
+	// the skipped e? was not generated, so it has no span
+	if len(s) != 2 || len(spans) != 2 {
+		t.Errorf("got %q with spans %v", s, spans)
+	}
+}
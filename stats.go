@@ -0,0 +1,83 @@
+package xeger
+
+import "unicode/utf8"
+
+// statsBuckets is the most buckets a LengthStats histogram has.
+const statsBuckets = 10
+
+// LengthStats summarises the rune lengths of a batch of generated strings.
+type LengthStats struct {
+	// Count is the number of strings measured.
+	Count int
+	Min   int
+	Max   int
+	Mean  float64
+
+	// Histogram counts lengths in buckets of BucketWidth lengths each,
+	// bucket i covering from Min+i*BucketWidth. There are at most ten.
+	BucketWidth int
+	Histogram   []int
+}
+
+// GenerateWithStats generates n strings like GenerateN and summarises
+// their lengths, for tuning repetition caps. Elements whose generation
+// fails are left empty and not measured.
+func (x *Xeger) GenerateWithStats(n int) ([]string, LengthStats) {
+	out := make([]string, n)
+	var lengths []int
+	for i := range out {
+		s, err := x.Generate()
+		if err != nil {
+			continue
+		}
+		out[i] = s
+		lengths = append(lengths, utf8.RuneCountInString(s))
+	}
+	return out, lengthStats(lengths)
+}
+
+// SampleDistribution generates n strings like GenerateN and counts how
+// often each value occurs, for checking that a small finite pattern such
+// as [ab]{2} is generated uniformly, or that weighting and bias options
+// skew it as intended. Like Generate, it draws from the instance's random
+// source, so results are reproducible under WithSeed. Strings whose
+// generation fails are not counted, so the counts may sum to less than n.
+func (x *Xeger) SampleDistribution(n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		s, err := x.Generate()
+		if err != nil {
+			continue
+		}
+		counts[s]++
+	}
+	return counts
+}
+
+// lengthStats summarises lengths.
+func lengthStats(lengths []int) LengthStats {
+	st := LengthStats{Count: len(lengths)}
+	if len(lengths) == 0 {
+		return st
+	}
+	st.Min, st.Max = lengths[0], lengths[0]
+	total := 0
+	for _, l := range lengths {
+		if l < st.Min {
+			st.Min = l
+		}
+		if l > st.Max {
+			st.Max = l
+		}
+		total += l
+	}
+	st.Mean = float64(total) / float64(len(lengths))
+
+	span := st.Max - st.Min + 1
+	st.BucketWidth = (span + statsBuckets - 1) / statsBuckets
+	st.Histogram = make([]int, (span+st.BucketWidth-1)/st.BucketWidth)
+	for _, l := range lengths {
+		st.Histogram[(l-st.Min)/st.BucketWidth]++
+	}
+	return st
+}
@@ -0,0 +1,81 @@
+package xeger
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestLengthStats(t *testing.T) {
+	var tests = []struct {
+		Lengths []int
+		Want    LengthStats
+	}{
+		{nil, LengthStats{}},
+		{[]int{3, 3}, LengthStats{Count: 2, Min: 3, Max: 3, Mean: 3, BucketWidth: 1, Histogram: []int{2}}},
+		{[]int{1, 2, 2, 4}, LengthStats{Count: 4, Min: 1, Max: 4, Mean: 2.25, BucketWidth: 1, Histogram: []int{1, 2, 0, 1}}},
+		{[]int{0, 5, 19, 20}, LengthStats{Count: 4, Min: 0, Max: 20, Mean: 11, BucketWidth: 3, Histogram: []int{1, 1, 0, 0, 0, 0, 2}}},
+	}
+
+	for _, test := range tests {
+		if got := lengthStats(test.Lengths); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("lengthStats(%v) = %+v, want %+v", test.Lengths, got, test.Want)
+		}
+	}
+}
+
+func TestGenerateWithStats(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-zé]{2,30}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, st := iRe.GenerateWithStats(500)
+	if len(out) != 500 || st.Count != 500 {
+		t.Fatalf("got %d strings and %d measured, want 500", len(out), st.Count)
+	}
+	total := 0
+	for _, s := range out {
+		n := utf8.RuneCountInString(s)
+		if n < st.Min || n > st.Max {
+			t.Fatalf("%q is outside [%d, %d]", s, st.Min, st.Max)
+		}
+		total += n
+	}
+	if st.Min < 2 || st.Max > 30 || st.Mean != float64(total)/500 {
+		t.Errorf("implausible stats %+v", st)
+	}
+	sum := 0
+	for _, c := range st.Histogram {
+		sum += c
+	}
+	if sum != 500 || len(st.Histogram) > 10 {
+		t.Errorf("histogram %v does not cover the batch", st.Histogram)
+	}
+}
+
+func TestSampleDistribution(t *testing.T) {
+	sample := func(opts ...Option) map[string]int {
+		iRe, err := NewInverseRegex(`[ab]{2}`, append(opts, WithSeed(1))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return iRe.SampleDistribution(4000)
+	}
+	counts := sample()
+	if len(counts) != 4 {
+		t.Fatalf("got %v, want all four values", counts)
+	}
+	for v, c := range counts {
+		if c < 850 || c > 1150 {
+			t.Errorf("%q: got %d of 4000, want about 1000", v, c)
+		}
+	}
+	if again := sample(); !reflect.DeepEqual(again, counts) {
+		t.Errorf("same seed gave %v then %v", counts, again)
+	}
+
+	weighted := sample(WithRuneWeights(map[rune]float64{'a': 9, 'b': 1}))
+	if weighted["aa"] < 3000 || weighted["bb"] > 100 {
+		t.Errorf("weighting a 9 to 1 gave %v", weighted)
+	}
+}
@@ -0,0 +1,63 @@
+package xeger
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// A URLComponent is a part of a URL that generated output can be encoded
+// for with WithURLEncode.
+type URLComponent int
+
+const (
+	// URLPath escapes a whole path, leaving its / separators intact.
+	URLPath URLComponent = iota + 1
+	// URLPathSegment escapes a single path segment, including any /.
+	URLPathSegment
+	// URLQuery escapes a query key or value, writing spaces as +.
+	URLQuery
+	// URLFragment escapes the fragment after a #.
+	URLFragment
+)
+
+// WithURLEncode percent-encodes every generated string for use as the URL
+// component c, as net/url does, so that a path pattern such as
+// /files/[^/]{1,12} gives strings ready to append to a base URL.
+func WithURLEncode(c URLComponent) Option {
+	return func(x *Xeger) error {
+		switch c {
+		case URLPath:
+			x.encodeURL = func(s string) string { return (&url.URL{Path: s}).EscapedPath() }
+		case URLPathSegment:
+			x.encodeURL = url.PathEscape
+		case URLQuery:
+			x.encodeURL = url.QueryEscape
+		case URLFragment:
+			x.encodeURL = func(s string) string { return (&url.URL{Fragment: s}).EscapedFragment() }
+		default:
+			return fmt.Errorf("xeger: unknown URL component %d", c)
+		}
+		return nil
+	}
+}
+
+// encode replaces the string generated into b from start onwards with its
+// escaped form for a regular expression, then its URL encoding and then
+// its shell quoting, as far as each is configured.
+func (x *Xeger) encode(b []byte, start int) []byte {
+	if x.encodeURL == nil && !x.regexSafe && x.shellQuote == nil {
+		return b
+	}
+	s := string(b[start:])
+	if x.regexSafe {
+		s = regexp.QuoteMeta(s)
+	}
+	if x.encodeURL != nil {
+		s = x.encodeURL(s)
+	}
+	if x.shellQuote != nil {
+		s = x.shellQuote(s)
+	}
+	return append(b[:start], s...)
+}
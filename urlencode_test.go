@@ -0,0 +1,60 @@
+package xeger
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWithURLEncode(t *testing.T) {
+	var tests = []struct {
+		Component URLComponent
+		Decode    func(string) (string, error)
+		Escaped   string
+	}{
+		{URLPath, url.PathUnescape, " ?#é"},
+		{URLPathSegment, url.PathUnescape, " ?#/é"},
+		{URLQuery, url.QueryUnescape, " ?&=#/é"},
+		{URLFragment, url.PathUnescape, " #é"},
+	}
+
+	const pattern = `/[a-z ]{1,4}/[?&=#%/é]{1,4}`
+	for _, test := range tests {
+		raw, err := NewInverseRegex(pattern, WithSeed(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := NewInverseRegex(pattern, WithSeed(1), WithURLEncode(test.Component))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			want, _ := raw.Generate()
+			got, err := enc.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.ContainsAny(got[1:], test.Escaped) {
+				t.Fatalf("component %d: %q is not escaped", test.Component, got)
+			}
+			if back, err := test.Decode(got); err != nil || back != want {
+				t.Fatalf("component %d: %q decodes to %q, %v, want %q", test.Component, got, back, err, want)
+			}
+		}
+	}
+
+	if _, err := NewInverseRegex(`a`, WithURLEncode(0)); err == nil {
+		t.Errorf("expected an error for an unknown component")
+	}
+}
+
+func TestWithURLEncodeChecksRaw(t *testing.T) {
+	iRe, err := NewInverseRegex(`[ ]{2}`, WithURLEncode(URLPathSegment), WithLengthRange(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := iRe.Generate()
+	if err != nil || s != "%20%20" {
+		t.Errorf("got %q, %v, want %%20%%20", s, err)
+	}
+}
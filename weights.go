@@ -0,0 +1,97 @@
+package xeger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// runeWeight is the relative weight of one rune in char class picks.
+type runeWeight struct {
+	r rune
+	w float64
+}
+
+// WithRuneWeights biases char class picks by relative weight, such as
+// English letter frequencies to make [a-z]+ look more like text. Runes
+// missing from weights have weight 1, so a class mixing listed and
+// unlisted runes weighs each listed rune against every unlisted one. A
+// weight of 0 excludes a rune unless the class has nothing else. Classes
+// containing no listed runes are unaffected. It is an error for a weight
+// to be negative, infinite or NaN.
+func WithRuneWeights(weights map[rune]float64) Option {
+	return func(x *Xeger) error {
+		ws := make([]runeWeight, 0, len(weights))
+		for r, w := range weights {
+			if w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+				return fmt.Errorf("xeger: invalid weight %v for %q", w, r)
+			}
+			ws = append(ws, runeWeight{r, w})
+		}
+		sort.Slice(ws, func(i, j int) bool { return ws[i].r < ws[j].r })
+		x.runeWeights = ws
+		return nil
+	}
+}
+
+// drawWeighted returns a rune from ranges chosen by the configured rune
+// weights, or false if ranges contain no weighted rune or all weights in
+// them are zero with no unlisted runes to fall back on.
+func (g *generator) drawWeighted(ranges []rune) (rune, bool) {
+	var listed []runeWeight
+	total := 0.0
+	for _, rw := range g.x.runeWeights {
+		if inRanges(ranges, rw.r) {
+			listed = append(listed, rw)
+			total += rw.w
+		}
+	}
+	if len(listed) == 0 {
+		return 0, false
+	}
+	unlisted := classSize(ranges) - int64(len(listed))
+	total += float64(unlisted)
+	if total == 0 {
+		return 0, false
+	}
+
+	u := g.rng.Float64() * total
+	for _, rw := range listed {
+		if u < rw.w {
+			return rw.r, true
+		}
+		u -= rw.w
+	}
+	if unlisted == 0 {
+		// rounding left u just past the last weight
+		return listed[len(listed)-1].r, true
+	}
+	return nthUnlisted(ranges, listed, g.rng.Int63n(unlisted)), true
+}
+
+// nthUnlisted returns the n'th rune of ranges, counting from 0 and
+// skipping the runes in listed, which are sorted and all within ranges.
+func nthUnlisted(ranges []rune, listed []runeWeight, n int64) rune {
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		var in []runeWeight
+		for _, rw := range listed {
+			if rw.r >= lo && rw.r <= hi {
+				in = append(in, rw)
+			}
+		}
+		size := int64(hi-lo) + 1 - int64(len(in))
+		if n >= size {
+			n -= size
+			continue
+		}
+		r := lo + rune(n)
+		for _, rw := range in {
+			if rw.r <= r {
+				r++
+			}
+		}
+		return r
+	}
+	panic("unreachable")
+}
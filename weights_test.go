@@ -0,0 +1,87 @@
+package xeger
+
+import "testing"
+
+func TestWithRuneWeights(t *testing.T) {
+	english := map[rune]float64{
+		'a': 8.2, 'b': 1.5, 'c': 2.8, 'd': 4.3, 'e': 12.7, 'f': 2.2, 'g': 2.0,
+		'h': 6.1, 'i': 7.0, 'j': 0.15, 'k': 0.77, 'l': 4.0, 'm': 2.4, 'n': 6.7,
+		'o': 7.5, 'p': 1.9, 'q': 0.095, 'r': 6.0, 's': 6.3, 't': 9.1, 'u': 2.8,
+		'v': 0.98, 'w': 2.4, 'x': 0.15, 'y': 2.0, 'z': 0.074,
+	}
+	iRe, err := NewInverseRegex(`[a-z]{1000}`, WithSeed(1), WithRuneWeights(english))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := sampleRunes(t, iRe, 10)
+	for _, r := range "abcdfghijklmnopqrsuvwxyz" {
+		if counts[r] >= counts['e'] || counts[r] >= counts['t'] {
+			t.Errorf("%q appeared %d times, against e %d and t %d", r, counts[r], counts['e'], counts['t'])
+		}
+	}
+	if counts['e'] < 1000 || counts['e'] > 1550 {
+		t.Errorf("e appeared %d times, want about 1270", counts['e'])
+	}
+}
+
+func TestRuneWeightsUnlisted(t *testing.T) {
+	// x carries as much weight as the nine other digits together, and 0
+	// is excluded.
+	iRe, err := NewInverseRegex(`[0-9x]{1000}`, WithSeed(1), WithRuneWeights(map[rune]float64{'x': 9, '0': 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := sampleRunes(t, iRe, 10)
+	if counts['0'] != 0 {
+		t.Errorf("excluded 0 appeared %d times", counts['0'])
+	}
+	if counts['x'] < 4500 || counts['x'] > 5500 {
+		t.Errorf("x appeared %d times, want about 5000", counts['x'])
+	}
+	for r := '1'; r <= '9'; r++ {
+		if counts[r] < 400 || counts[r] > 700 {
+			t.Errorf("%q appeared %d times, want about 555", r, counts[r])
+		}
+	}
+
+	// a class of only zero-weight runes falls back to a uniform pick
+	iRe, err = NewInverseRegex(`[ab]`, WithRuneWeights(map[rune]float64{'a': 0, 'b': 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iRe.GenerateValid(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewInverseRegex(`[ab]`, WithRuneWeights(map[rune]float64{'a': -1})); err == nil {
+		t.Error("expected an error for a negative weight")
+	}
+}
+
+func TestNthUnlisted(t *testing.T) {
+	ranges := []rune{'a', 'e', 'x', 'z'}
+	listed := []runeWeight{{'a', 1}, {'c', 1}, {'y', 1}}
+	var got []rune
+	for n := int64(0); n < 5; n++ {
+		got = append(got, nthUnlisted(ranges, listed, n))
+	}
+	if string(got) != "bdexz" {
+		t.Errorf("got %q, want %q", string(got), "bdexz")
+	}
+}
+
+// sampleRunes counts the runes in n strings generated by iRe.
+func sampleRunes(t *testing.T, iRe *Xeger, n int) map[rune]int {
+	t.Helper()
+	counts := make(map[rune]int)
+	for i := 0; i < n; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range s {
+			counts[r]++
+		}
+	}
+	return counts
+}
@@ -1,250 +1,976 @@
 package xeger
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"math/rand"
 	"regexp"
 	"regexp/syntax"
 	"strconv"
+	"sync"
+	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 type Xeger struct {
-	re     *syntax.Regexp
-	logger Logger
+	pattern string
+	re      *syntax.Regexp
+	logger  Logger
+
+	// regexp is the source pattern compiled with anchors on both ends,
+	// so that it only reports whole-string matches. search is the pattern
+	// compiled as given, for finding matches within a larger string.
+	regexp *regexp.Regexp
+	search *regexp.Regexp
+
+	// seed is the base seed. rng is derived from it and guarded by mu.
+	seed int64
+	mu   sync.Mutex
+	rng  *rand.Rand
+
+	// seeded records that seed was set with WithSeed, which takes
+	// precedence over seedFromPattern.
+	seeded, seedFromPattern bool
+
+	// checks are extra constraints a generated string must pass. Strings
+	// failing any check are re-rolled, up to maxRetries attempts.
+	checks     []func(string) bool
+	maxRetries int
+
+	distinctAlternates bool
+	variety            bool
+
+	// captureValues holds fixed values for named captures, emitted in
+	// place of generated content.
+	captureValues map[string]string
+
+	// encodeURL, when set, percent-encodes each accepted string, after
+	// regexSafe escapes its regexp metacharacters.
+	encodeURL func(string) string
+	regexSafe bool
+
+	// shellQuote, when set, quotes each accepted string for a shell,
+	// after any URL encoding.
+	shellQuote func(string) string
+
+	// printable makes rune picks prefer printable runes.
+	printable bool
+
+	// allOptionals makes quantifiers that may be skipped repeat at least
+	// once, with nonEmpty listing, for each alternation with an empty
+	// branch, the branches that are not.
+	allOptionals bool
+	nonEmpty     map[*syntax.Regexp][]int
+
+	// compact in [0, 1] biases choices towards shorter output, with
+	// shortest holding the branch each alternation then favours.
+	compact  float64
+	shortest map[*syntax.Regexp]int
+
+	// tooDeep holds the quantifiers nested beyond WithStructuralDepth,
+	// which repeat their minimum.
+	tooDeep map[*syntax.Regexp]bool
+
+	// exactRepeats pins the counts of quantifiers set by
+	// WithExactRepeat.
+	exactRepeats map[*syntax.Regexp]int
+
+	// maxDistinct, when positive, is how many distinct runes a string
+	// may use before picks favour runes it already has.
+	maxDistinct int
+
+	// lengthSample, when set, draws the length each string is steered
+	// towards.
+	lengthSample func() int
+
+	// maxLength, unless -1, is the budget in runes every string is
+	// steered to stay within.
+	maxLength int
+
+	// pool, when set, holds the random sources Generate draws from
+	// instead of rng.
+	pool *sync.Pool
+
+	// growLevel is the repetition cap of the next GenerateGrowing call,
+	// guarded by mu.
+	growLevel int
+
+	// shuffle permutes the output of GenerateN.
+	shuffle bool
+
+	// coverage, when non-nil, tracks the alternation branches chosen
+	// across calls, guarded by mu.
+	coverage map[*syntax.Regexp]*dealer
+
+	// maxDepth bounds how deeply generation recurses into the tree.
+	maxDepth int
+
+	// zeroWidth is set when the pattern can only match the empty string.
+	zeroWidth bool
+
+	// noise surrounds each match with random text that does not match.
+	noise bool
+
+	// maxReps caps how far an unbounded quantifier repeats beyond its
+	// minimum, and repStrategy picks counts within the resulting bounds.
+	// A nil repStrategy uses the default described at WithRepStrategy.
+	maxReps     int
+	repStrategy RepStrategy
+
+	// captureLengths bounds the rune length of named captures' content.
+	captureLengths map[string][2]int
+
+	// markers, when set, are the open and close strings written around
+	// each capture group's content.
+	markers []string
+
+	// whitespaceRune, when non-zero, is emitted for every char class
+	// consisting only of whitespace that contains it.
+	whitespaceRune rune
+
+	// foldStrategy chooses the case of case-insensitive literals.
+	foldStrategy FoldStrategy
+
+	// classSizes holds the cumulative range sizes of large char classes.
+	classSizes map[*syntax.Regexp][]int64
+
+	// questProb is the probability that a ? includes its content.
+	questProb float64
+
+	// newlineProb is the probability that a . matching newlines emits one.
+	newlineProb float64
+
+	// runeWeights, sorted by rune, bias char class picks towards or
+	// away from the listed runes.
+	runeWeights []runeWeight
+
+	// edgeBias is the probability that a char class pick is forced to
+	// the endpoint of one of its ranges.
+	edgeBias float64
+}
+
+// generator holds the state of a single generation walk over the tree.
+type generator struct {
+	x   *Xeger
+	rng *rand.Rand
+	buf []byte
+
+	// logging is false when x has the default nopLogger, so that the walk
+	// skips formatting log arguments nobody will read.
+	logging bool
+
+	// maxReps and repStrategy start out as configured on x but may be
+	// overridden for part of a walk.
+	maxReps     int
+	repStrategy RepStrategy
+
+	// placeholder, when set, gives the text written in place of each
+	// named capture's content.
+	placeholder func(name string) string
+
+	// captureValues starts out as x.captureValues, with any values passed
+	// to GenerateWithTemplate on top.
+	captureValues map[string]string
+
+	// dealt tracks the choices already made at each alternation and char
+	// class within the innermost repeat, when distinct alternates are
+	// enabled. It is nil outside of a repeat.
+	dealt map[*syntax.Regexp]*dealer
+
+	// check is an extra per-call constraint, alongside those configured
+	// on x. It may be nil.
+	check func(string) bool
+
+	// allow, when set, restricts random rune picks to runes it accepts
+	// wherever the pattern leaves a choice.
+	allow func(rune) bool
+
+	// variety tracks the choices made at each alternation, char class
+	// and quantifier across a whole string, when WithMaximizeVariety is
+	// set.
+	variety map[*syntax.Regexp]*dealer
+
+	// seen holds the distinct runes in buf since matchStart, up to
+	// seenUpTo, when WithMaxDistinctRunes is set.
+	seen     map[rune]bool
+	seenUpTo int
+
+	// diverse tracks the runes chosen at each char class across a whole
+	// batch, for GenerateDiverse. It is nil otherwise.
+	diverse map[*syntax.Regexp]*dealer
+
+	// recording makes the walk log its random decisions in decisions.
+	// replaying makes it take them from replay instead of the random
+	// source, reading from replayPos onwards.
+	recording bool
+	decisions []Decision
+	replaying bool
+	replay    []Decision
+	replayPos int
+
+	// depth is how deeply makeMatch calls are currently nested.
+	depth int
+
+	// matchStart is the offset in buf where the current attempt's match
+	// begins. Span and class pick offsets are relative to it.
+	matchStart int
+
+	// beginText and beginLine record whether the walk passed a \A or
+	// multiline ^ before emitting anything, and endText and endLine the
+	// length of buf when it last passed a \z or multiline $, or -1. Only
+	// tracked with surrounding noise, which must keep clear of them.
+	beginText, beginLine bool
+	endText, endLine     int
+
+	// steer, when non-nil, guides choices towards an exact byte length.
+	steer *steering
+
+	// recordSpans makes the walk record in spans where the output of
+	// each top-level part of the pattern lies, and leafSpans where the
+	// output of each leaf node lies instead.
+	recordSpans bool
+	leafSpans   bool
+	spans       []Span
+
+	// classSites, when non-nil, numbers the char classes of the pattern
+	// and makes the walk record each pick from them in classPicks.
+	classSites map[*syntax.Regexp]int
+	classPicks []ClassPick
+
+	// visits, when non-nil, records the nodes visited by the current
+	// attempt, and covered those visited by earlier strings of an op
+	// coverage set. Choices then favour nodes in neither.
+	visits  map[*syntax.Regexp]bool
+	covered map[*syntax.Regexp]bool
+
+	// shape, when non-nil, receives the branch taken at each alternation
+	// and whether each optional part was present, except while shapeMuted
+	// is positive inside the second and later copies of a repeat.
+	shape      []int
+	shapeMuted int
+
+	// coverage is x.coverage for walks drawing from the instance RNG,
+	// and nil otherwise.
+	coverage map[*syntax.Regexp]*dealer
+
+	// captures, when non-nil, receives the content generated for each
+	// capture during the current attempt, keyed as GenerateWithCaptures
+	// documents.
+	captures map[string]string
+}
+
+// Generate returns a string that should be matched by the regular
+// expression x was built from. An error is returned if the pattern uses
+// an operation the generator does not support.
+func (x *Xeger) Generate() (string, error) {
+	if x.pool != nil {
+		rng := x.pool.Get().(*rand.Rand)
+		defer x.pool.Put(rng)
+		return x.generate(rng)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.generate(x.rng)
 }
 
-func (x *Xeger) Generate() string {
-	x.logger.Printf("regex: %s", x.re.String())
-	x.logger.Printf("sub %v", x.re.Sub)
+// GenerateWith is like Generate but draws every random decision from rng
+// and mutates no state of x. Calls are safe to make concurrently as long as
+// each uses its own rng.
+func (x *Xeger) GenerateWith(rng *rand.Rand) (string, error) {
+	g := x.newGenerator(rng)
+	// coverage bias deals from state shared with the instance RNG
+	g.coverage = nil
+	return g.generate()
+}
+
+// generate returns a string passing all checks, drawing random decisions
+// from rng.
+func (x *Xeger) generate(rng *rand.Rand) (string, error) {
+	return x.newGenerator(rng).generate()
+}
+
+// newGenerator returns a generator drawing random decisions from rng, with
+// overridable settings taken from x.
+func (x *Xeger) newGenerator(rng *rand.Rand) *generator {
+	_, nop := x.logger.(nopLogger)
+	g := &generator{
+		x:             x,
+		rng:           rng,
+		logging:       !nop,
+		maxReps:       x.maxReps,
+		repStrategy:   x.repStrategy,
+		captureValues: x.captureValues,
+	}
+	if rng == x.rng {
+		g.coverage = x.coverage
+	}
+	var allow []func(rune) bool
+	if x.regexSafe {
+		allow = append(allow, notRegexMeta)
+	}
+	if x.printable {
+		allow = append(allow, unicode.IsPrint)
+	}
+	if x.shellQuote != nil {
+		allow = append(allow, notShellMeta)
+	}
+	g.allow = allowAll(allow)
+	if x.variety {
+		g.variety = make(map[*syntax.Regexp]*dealer)
+	}
+	if x.maxDistinct > 0 {
+		g.seen = make(map[rune]bool)
+	}
+	switch {
+	case x.lengthSample != nil:
+		lo, hi := lengthBounds(x.re)
+		if x.maxLength != -1 && (hi == -1 || hi > x.maxLength) {
+			hi = x.maxLength
+		}
+		g.steer = &steering{sample: x.lengthSample, lo: lo, hi: hi, bounds: make(map[*syntax.Regexp][2]int)}
+	case x.maxLength != -1:
+		g.steer = &steering{target: x.maxLength, capped: true, bounds: make(map[*syntax.Regexp][2]int)}
+	}
+	return g
+}
+
+// generate returns a string passing all checks.
+func (g *generator) generate() (string, error) {
+	b, err := g.appendGenerated(nil)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// appendGenerated appends a string passing all checks to dst. Rejected
+// attempts are truncated away so their space in dst is reused.
+func (g *generator) appendGenerated(dst []byte) ([]byte, error) {
+	x := g.x
+	start := len(dst)
+	sampled := g.steer != nil && g.steer.sample != nil
+	if sampled {
+		g.steer.draw()
+	}
+	for i := 0; i < x.maxRetries; i++ {
+		out, err := g.appendOnce(dst[:start])
+		if err != nil {
+			return dst[:start], err
+		}
+		if sampled && i < x.maxRetries-1 && !g.steer.hit(out[start:]) {
+			dst = out
+			continue
+		}
+		if len(x.checks) == 0 && g.check == nil {
+			return x.encode(out, start), nil
+		}
+		if s := string(out[start:]); x.accept(s) && (g.check == nil || g.check(s)) {
+			return x.encode(out, start), nil
+		}
+		dst = out
+	}
+	return dst[:start], fmt.Errorf("%w: no acceptable string after %d attempts", ErrRetryExhausted, x.maxRetries)
+}
+
+// accept reports whether s passes every configured check.
+func (x *Xeger) accept(s string) bool {
+	for _, check := range x.checks {
+		if !check(s) {
+			return false
+		}
+	}
+	return true
+}
 
-	var regexStr string
-	for _, r := range x.re.Sub {
-		x.logger.Println(">")
-		regexStr += makeMatch(*r)
-		x.logger.Println(r.String())
-		x.logger.Printf("\t op   %s [%v]", OpName(r.Op), r.Op)
-		x.logger.Printf("\t rune %v", string(r.Rune))
+// appendOnce performs one walk of the tree, appending the result to dst.
+func (g *generator) appendOnce(dst []byte) ([]byte, error) {
+	x := g.x
+	if g.logging {
+		x.logger.Printf("regex: %s", x.re)
 	}
-	x.logger.Printf("potenially match: `%s`", regexStr)
-	x.logger.Println()
 
-	return regexStr
+	g.buf = dst
+	g.rewind(0)
+	if g.steer != nil {
+		g.steer.reset(len(dst))
+	}
+	for name := range g.captures {
+		delete(g.captures, name)
+	}
+	for re := range g.visits {
+		delete(g.visits, re)
+	}
+	for re := range g.variety {
+		delete(g.variety, re)
+	}
+	for r := range g.seen {
+		delete(g.seen, r)
+	}
+	g.seenUpTo = len(dst)
+	if g.shape != nil {
+		g.shape = g.shape[:0]
+	}
+	g.matchStart = len(dst)
+	g.beginText, g.beginLine, g.endText, g.endLine = false, false, -1, -1
+	g.spans = g.spans[:0]
+	g.classPicks = g.classPicks[:0]
+	switch {
+	case g.recordSpans:
+		if err := g.spanMatch(x.re); err != nil {
+			return dst, err
+		}
+	case !x.zeroWidth || x.markers != nil || x.noise:
+		// A pattern of only empty matches and zero-width assertions
+		// always gives the empty string, so there is nothing to walk.
+		// Capture markers are the exception, being written even around
+		// empty groups, as is noise, which must see the anchors.
+		if err := g.makeMatch(x.re); err != nil {
+			return dst, err
+		}
+	}
+	if x.noise {
+		if err := g.surround(len(dst)); err != nil {
+			return dst, err
+		}
+	}
+	if g.logging {
+		x.logger.Printf("potenially match: `%s`", g.buf[len(dst):])
+		x.logger.Println()
+	}
+
+	return g.buf, nil
+}
+
+// GenerateValid is like Generate but additionally checks the result against
+// the compiled regular expression, returning an error if it does not match.
+func (x *Xeger) GenerateValid() (string, error) {
+	s, err := x.Generate()
+	if err != nil {
+		return "", err
+	}
+	if !x.matches(s) {
+		return "", fmt.Errorf("%w: %q against %s", ErrMismatch, s, x.re)
+	}
+	return s, nil
+}
+
+// GenerateSatisfying generates a string that matches the pattern and for
+// which pred returns true, such as a date pattern whose matches must also
+// be real calendar dates. Like the other constraints, failures are re-rolled
+// up to the retry limit.
+func (x *Xeger) GenerateSatisfying(pred func(string) bool) (string, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g := x.newGenerator(x.rng)
+	g.check = func(s string) bool { return x.matches(s) && pred(s) }
+	return g.generate()
+}
+
+// GenerateDecodable generates a string that matches the pattern and that
+// decode accepts, such as a JSON-ish pattern whose output must also
+// unmarshal, so that the result is valid for the consumer and not only for
+// the regular expression. Strings decode rejects are re-rolled up to the
+// retry limit; if none is accepted the error wraps both ErrRetryExhausted
+// and the last error from decode.
+func (x *Xeger) GenerateDecodable(decode func(string) error) (string, error) {
+	var last error
+	s, err := x.GenerateSatisfying(func(s string) bool {
+		last = decode(s)
+		return last == nil
+	})
+	if errors.Is(err, ErrRetryExhausted) && last != nil {
+		return "", fmt.Errorf("%w: last decode error: %w", err, last)
+	}
+	return s, err
+}
+
+// GenerateChecked generates once and reports whether the result matches the
+// compiled regular expression, leaving it to the caller to decide what to
+// do with an invalid string. Unlike GenerateValid it never retries. A
+// failed generation is reported as an empty, invalid string.
+func (x *Xeger) GenerateChecked() (s string, valid bool) {
+	s, err := x.Generate()
+	if err != nil {
+		return "", false
+	}
+	return s, x.matches(s)
+}
+
+// CanGenerate reports whether the pattern, with its configured options,
+// can be generated from, by making a generation attempt and discarding the
+// result. It returns the error Generate would, such as ErrUnsupportedOp or
+// ErrRetryExhausted. The attempt draws from its own random source seeded
+// by the base seed, leaving the instance's sequence undisturbed, so the
+// answer is the same every time it is asked.
+func (x *Xeger) CanGenerate() error {
+	_, err := x.generate(rand.New(rand.NewSource(x.seed)))
+	return err
 }
 
-func NewInverseRegex(s string) (*Xeger, error) {
-	_, err := regexp.Compile(s)
+// matches reports whether s is a valid result: a whole-string match, or
+// with surrounding noise, a string containing a match.
+func (x *Xeger) matches(s string) bool {
+	if x.noise {
+		return x.search.MatchString(s)
+	}
+	return x.regexp.MatchString(s)
+}
+
+// NewInverseRegex parses s and returns a Xeger generating strings it
+// matches, configured by opts.
+func NewInverseRegex(s string, opts ...Option) (*Xeger, error) {
+	search, err := regexp.Compile(s)
+	if err != nil {
+		return nil, explainParseError(s, err)
+	}
+	// The tree is deliberately not simplified: Simplify expands counted
+	// repeats into copies and nested quests, losing the counts we
+	// generate from.
+	re, err := syntax.Parse(s, syntax.Perl)
 	if err != nil {
 		return nil, err
 	}
-	re, err := syntax.Parse(s, syntax.POSIX)
+	return newXeger(s, re, search, opts)
+}
+
+// NewFromSyntax returns a Xeger generating strings matched by re, a tree
+// parsed by the caller, perhaps with flags of their own or simplified or
+// otherwise transformed, without parsing it again. The caller is
+// responsible for re being a valid tree such as syntax.Parse returns. The
+// Xeger works on its own copy, so re is left as it is and may be reused.
+// Pattern reports re.String(). It returns nil if an option fails or if
+// re.String() does not compile, as for a repeat count past 1000 or a
+// hand-built node the parser would never produce; FromSyntax reports why.
+func NewFromSyntax(re *syntax.Regexp, opts ...Option) *Xeger {
+	x, err := FromSyntax(re, opts...)
+	if err != nil {
+		return nil
+	}
+	return x
+}
+
+// FromSyntax is like NewFromSyntax but returns an error, rather than nil,
+// if re does not compile or an option fails.
+func FromSyntax(re *syntax.Regexp, opts ...Option) (*Xeger, error) {
+	pattern := re.String()
+	search, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("xeger: tree %s does not compile: %w", pattern, err)
+	}
+	return newXeger(pattern, copyTree(re), search, opts)
+}
+
+// copyTree returns a deep copy of re, so that it can be changed without
+// affecting the caller's tree.
+func copyTree(re *syntax.Regexp) *syntax.Regexp {
+	c := *re
+	if re.Rune != nil {
+		c.Rune = append([]rune(nil), re.Rune...)
+	}
+	if re.Sub != nil {
+		c.Sub = make([]*syntax.Regexp, len(re.Sub))
+		for i, sub := range re.Sub {
+			c.Sub[i] = copyTree(sub)
+		}
+	}
+	return &c
+}
+
+// newXeger returns a Xeger for the parsed pattern re, the tree of
+// pattern, with search its unanchored compiled form.
+func newXeger(pattern string, re *syntax.Regexp, search *regexp.Regexp, opts []Option) (*Xeger, error) {
+	// Anchor the parsed form rather than the pattern itself, which may end
+	// inside an unterminated \Q quote.
+	full, err := regexp.Compile(`^(?:` + re.String() + `)$`)
 	if err != nil {
 		return nil, err
 	}
-	simp := re.Simplify()
+	dropSurrogates(re)
+	sizes := make(map[*syntax.Regexp][]int64)
+	classSizes(re, sizes)
 
-	return &Xeger{re: simp, logger: nopLogger{}}, nil
+	x := &Xeger{
+		pattern:     pattern,
+		re:          re,
+		logger:      nopLogger{},
+		regexp:      full,
+		search:      search,
+		seed:        time.Now().UnixNano(),
+		maxRetries:  defaultMaxRetries,
+		maxReps:     defaultMaxReps,
+		questProb:   defaultQuestProbability,
+		newlineProb: defaultNewlineProbability,
+		maxDepth:    defaultMaxDepth,
+		maxLength:   -1,
+		zeroWidth:   zeroWidth(re),
+		classSizes:  sizes,
+	}
+	for _, opt := range opts {
+		if err := opt(x); err != nil {
+			return nil, err
+		}
+	}
+	if x.seedFromPattern && !x.seeded {
+		x.seed = patternSeed(pattern)
+	}
+	x.rng = rand.New(rand.NewSource(x.seed))
+
+	return x, nil
 }
 
+// OpName returns the name of the syntax.Op constant op, such as
+// "OpLiteral", or "OpUnknown" if op is not one of them.
 func OpName(op syntax.Op) string {
 	switch op {
-	case 1:
+	case syntax.OpNoMatch:
 		return "OpNoMatch"
-	case 2:
+	case syntax.OpEmptyMatch:
 		return "OpEmptyMatch"
-	case 3:
+	case syntax.OpLiteral:
 		return "OpLiteral"
-	case 4:
+	case syntax.OpCharClass:
 		return "OpCharClass"
-	case 5:
+	case syntax.OpAnyCharNotNL:
 		return "OpAnyCharNotNL"
-	case 6:
+	case syntax.OpAnyChar:
 		return "OpAnyChar"
-	case 7:
+	case syntax.OpBeginLine:
 		return "OpBeginLine"
-	case 8:
+	case syntax.OpEndLine:
+		return "OpEndLine"
+	case syntax.OpBeginText:
 		return "OpBeginText"
-	case 9:
-		return "OpWrodBoundary"
-	case 10:
+	case syntax.OpEndText:
+		return "OpEndText"
+	case syntax.OpWordBoundary:
+		return "OpWordBoundary"
+	case syntax.OpNoWordBoundary:
 		return "OpNoWordBoundary"
-	case 11:
+	case syntax.OpCapture:
 		return "OpCapture"
-	case 12:
+	case syntax.OpStar:
 		return "OpStar"
-	case 13:
+	case syntax.OpPlus:
 		return "OpPlus"
-	case 14:
+	case syntax.OpQuest:
 		return "OpQuest"
-	case 15:
+	case syntax.OpRepeat:
 		return "OpRepeat"
-	case 16:
+	case syntax.OpConcat:
 		return "OpConcat"
-	case 17:
+	case syntax.OpAlternate:
 		return "OpAlternate"
 	default:
 		return "OpUnknown"
 	}
 }
 
-func makeMatch(re syntax.Regexp) string {
+// makeMatch appends a string matched by re to the buffer, recursing into
+// its subexpressions as needed. Every level of recursion counts against
+// the configured maximum depth.
+func (g *generator) makeMatch(re *syntax.Regexp) error {
+	if g.depth == g.x.maxDepth {
+		return fmt.Errorf("%w: %d levels at %s", ErrDepthExceeded, g.x.maxDepth, re)
+	}
+	g.depth++
+	if g.visits != nil {
+		g.visits[re] = true
+	}
+	start := len(g.buf)
+	err := g.match(re)
+	g.depth--
+	if g.leafSpans && len(re.Sub) == 0 && err == nil {
+		g.leafSpan(re, start)
+	}
+	return err
+}
+
+// match does the work of makeMatch for a single node.
+func (g *generator) match(re *syntax.Regexp) error {
+	x := g.x
+	if g.logging {
+		x.logger.Printf("\t op   %s [%v]", OpName(re.Op), re.Op)
+	}
 	switch re.Op {
 	default:
-		return fmt.Sprintf("<invalid op" + strconv.Itoa(int(re.Op)) + ">")
+		return fmt.Errorf("%w %s", ErrUnsupportedOp, OpName(re.Op))
 	case syntax.OpNoMatch:
-		log.Println("OpNoMatch")
-		return ""
+		return ErrNoMatch
 	case syntax.OpEmptyMatch:
-		log.Println("OpEmptyMatch")
-		return ""
+		return nil
 	case syntax.OpLiteral:
-		log.Println("OpLiteral")
-		if re.Flags&syntax.FoldCase != 0 {
-			// b.WriteString(`(?i:`)
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				v, err := g.decide(DecisionRune, int(r),
+					func() int { return int(g.foldCase(r)) },
+					func(v int) bool { return foldsTo(r, rune(v)) })
+				if err != nil {
+					return err
+				}
+				r = rune(v)
+			}
+			g.buf = utf8.AppendRune(g.buf, r)
 		}
-		return string(re.Rune)
+		return nil
 	case syntax.OpCharClass:
-		log.Println("OpCharClass")
-
-		// b.WriteRune('[')
 		if len(re.Rune) == 0 {
-			// b.WriteString(`^\x00-\x{10FFFF}`)
-		} else if re.Rune[0] == 0 && re.Rune[len(re.Rune)-1] == unicode.MaxRune {
-			// Contains 0 and MaxRune.  Probably a negated class.
-			// Print the gaps.
-			// b.WriteRune('^')
-			for i := 1; i < len(re.Rune)-1; i += 2 {
-				lo, hi := re.Rune[i]+1, re.Rune[i+1]-1
-				// escape(b, lo, lo == '-')
-				if lo != hi {
-					// b.WriteRune('-')
-					// escape(b, hi, hi == '-')
-				}
-			}
-		} else {
-			for i := 0; i < len(re.Rune); i += 2 {
-				lo, hi := re.Rune[i], re.Rune[i+1]
-				// escape(b, lo, lo == '-')
-				if lo != hi {
-					// b.WriteRune('-')
-					// escape(b, hi, hi == '-')
-				}
+			return fmt.Errorf("%w: empty character class %s", ErrNoMatch, re)
+		}
+		r := x.whitespaceRune
+		if r == 0 || !spaceOnly(re.Rune) || !inRanges(re.Rune, r) {
+			v, err := g.decide(DecisionRune, int(re.Rune[0]),
+				func() int { return int(g.classRune(re)) },
+				func(v int) bool { return inRanges(re.Rune, rune(v)) })
+			if err != nil {
+				return err
 			}
+			r = rune(v)
 		}
-		// b.WriteRune(']')
-	case syntax.OpAnyCharNotNL:
-		log.Println("OpAnyCharNotNL")
-		return "abc"
-	case syntax.OpAnyChar:
-		log.Println("OpAnyChar")
-		return "abc" // and sometimes nl
-	case syntax.OpBeginLine:
-		log.Println("OpBeginLine")
-		// b.WriteRune('^') // make sure this is first?
-	case syntax.OpEndLine:
-		log.Println("OpEndLine")
-		// b.WriteRune('$') // make sure this is last?
-	case syntax.OpBeginText:
-		log.Println("OpBeginText")
-		// b.WriteString(`\A`)
-	case syntax.OpEndText:
-		log.Println("OpEndText")
-		if re.Flags&syntax.WasDollar != 0 {
-			// b.WriteString(`(?-m:$)`)
-		} else {
-			// b.WriteString(`\z`)
+		if g.classSites != nil {
+			g.pickedClass(re, r, len(g.buf))
 		}
-	case syntax.OpWordBoundary:
-		log.Println("OpWordBoundary")
-		return " "
-	case syntax.OpNoWordBoundary:
-		log.Println("OpNoWordBoundary")
-		// b.WriteString(`\B`)
+		g.buf = utf8.AppendRune(g.buf, r)
+		return nil
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		nl := re.Op == syntax.OpAnyChar
+		v, err := g.decide(DecisionRune, ' ',
+			func() int { return int(g.anyRune(nl)) },
+			func(v int) bool { return utf8.ValidRune(rune(v)) && (nl || v != '\n') })
+		if err != nil {
+			return err
+		}
+		g.buf = utf8.AppendRune(g.buf, rune(v))
+		return nil
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		// Anchors and word boundaries are zero-width: they constrain
+		// where a match may sit but never contribute characters of
+		// their own.
+		if x.noise {
+			g.anchor(re.Op)
+		}
+		return nil
 	case syntax.OpCapture:
-		log.Println("OpCapture")
-		fallthrough
-		// if re.Name != "" {
-		// 	b.WriteString(`(?P<`)
-		// 	b.WriteString(re.Name)
-		// 	b.WriteRune('>')
-		// } else {
-		// 	b.WriteRune('(')
-		// }
-		// if re.Sub[0].Op != syntax.OpEmptyMatch {
-		// 	// writeRegexp(b, re.Sub[0])
-		// }
-		// b.WriteRune(')')
+		if x.markers == nil {
+			return g.capture(re)
+		}
+		g.buf = append(g.buf, x.markers[0]...)
+		if err := g.capture(re); err != nil {
+			return err
+		}
+		g.buf = append(g.buf, x.markers[1]...)
+		return nil
 	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
-		log.Println("OpRepeat")
-		if sub := re.Sub[0]; sub.Op > syntax.OpCapture || sub.Op == syntax.OpLiteral && len(sub.Rune) > 1 {
-			// b.WriteString(`(?:`)
-			// writeRegexp(b, sub)
-			log.Println("named inner stuff to expand")
-			// b.WriteString(`)`)
-		} else {
-			// writeRegexp(b, sub)
-			log.Println("inner stuff to expand")
-		}
-		// this is the logics!
-		thing := re.Sub
-		thing2 := re.Sub0
-
-		for _, t := range thing {
-			log.Printf(" _>> %v", t)
-		}
-
-		for _, t := range thing2 {
-			log.Printf(" 0>> %v", t)
-		}
-
-		switch re.Op {
-		case syntax.OpStar:
-			log.Println("OpStar")
-			str := string(re.Rune)
-			return str + str + str
-		case syntax.OpPlus:
-			log.Println("OpPlus")
-			log.Println("op plus...?")
-			return string(re.Rune)
-		case syntax.OpQuest:
-			log.Println("OpQuest")
-			return string(re.Rune)
-			// sometimes not
-		case syntax.OpRepeat:
-			log.Println("OpRepeat")
-			// b.WriteRune('{')
-			str := ""
-			for i := 0; i < re.Min; i++ {
-				str += string(re.Rune)
-			}
-			// consider rand between min and max
+		n, err := g.reps(re)
+		if err != nil {
+			return err
 		}
-		if re.Flags&syntax.NonGreedy != 0 {
-			// b.WriteRune('?')
+		if g.shape != nil && g.shapeMuted == 0 && optional(re) {
+			g.shape = append(g.shape, min(n, 1))
 		}
+		return g.repeat(re.Sub[0], n)
 	case syntax.OpConcat:
-		log.Println("OpConcat")
-		for _, sub := range re.Sub {
-			if sub.Op == syntax.OpAlternate {
-				// b.WriteString(`(?:`)
-				// writeRegexp(b, sub)
-				// b.WriteString(`)`)
-			} else {
-				// writeRegexp(b, sub)
+		if g.steer != nil {
+			return g.steerConcat(re)
+		}
+		for i := 0; i < len(re.Sub); i++ {
+			sub := re.Sub[i]
+			if plainLiteral(sub) {
+				// Simplify writes a{n} as n copies of a; emit a
+				// run of identical literals in one go.
+				n := 1
+				for i+n < len(re.Sub) && (re.Sub[i+n] == sub || re.Sub[i+n].Equal(sub)) {
+					n++
+				}
+				start := len(g.buf)
+				g.appendLiteral(sub.Rune, n)
+				g.literalRun(re.Sub[i:i+n], start)
+				i += n - 1
+				continue
+			}
+			if err := g.makeMatch(sub); err != nil {
+				return err
 			}
 		}
+		return nil
 	case syntax.OpAlternate:
-		log.Println("OpAlternate")
-		for i, sub := range re.Sub {
-			if i > 0 {
-				// this is specail. huh. sometimes write the second. What is the second?
-				// b.WriteRune('|')
-			}
-			_ = sub
-			// writeRegexp(b, sub)
+		i, err := g.decide(DecisionBranch, 0,
+			func() int {
+				if g.steer != nil {
+					if i, ok := g.steerBranch(re); ok {
+						return i
+					}
+				}
+				if g.visits != nil {
+					if i, ok := g.uncoveredBranch(re); ok {
+						return i
+					}
+				}
+				if fill, ok := g.x.nonEmpty[re]; ok {
+					return fill[g.rng.Intn(len(fill))]
+				}
+				if c := g.x.compact; c > 0 && g.rng.Float64() < c {
+					return g.x.shortest[re]
+				}
+				switch {
+				case g.dealt != nil:
+					return g.dealBranch(g.dealt, re)
+				case g.variety != nil:
+					return g.dealBranch(g.variety, re)
+				case g.coverage != nil:
+					return g.dealBranch(g.coverage, re)
+				}
+				return g.rng.Intn(len(re.Sub))
+			},
+			func(i int) bool { return i >= 0 && i < len(re.Sub) })
+		if err != nil {
+			return err
+		}
+		if g.shape != nil && g.shapeMuted == 0 {
+			g.shape = append(g.shape, i)
 		}
+		return g.makeMatch(re.Sub[i])
 	}
-	return ""
+}
+
+// capture generates the content of the capture group re.
+func (g *generator) capture(re *syntax.Regexp) error {
+	start := len(g.buf)
+	if err := g.captureContent(re); err != nil {
+		return err
+	}
+	if g.captures != nil {
+		key := re.Name
+		if key == "" {
+			key = strconv.Itoa(re.Cap)
+		}
+		g.captures[key] = string(g.buf[start:])
+	}
+	return nil
+}
+
+// captureContent generates the content of the capture re, honouring any
+// fixed value or length configured for it.
+func (g *generator) captureContent(re *syntax.Regexp) error {
+	if g.placeholder != nil && re.Name != "" {
+		g.buf = append(g.buf, g.placeholder(re.Name)...)
+		return nil
+	}
+	if v, ok := g.captureValues[re.Name]; ok {
+		g.buf = append(g.buf, v...)
+		return nil
+	}
+	if bounds, ok := g.x.captureLengths[re.Name]; ok {
+		return g.captureWithLength(re, bounds)
+	}
+	return g.makeMatch(re.Sub[0])
+}
+
+// foldRune returns a random member of the case folding orbit of r, such as
+// one of Σ, σ and ς for σ. Walking the orbit with unicode.SimpleFold handles
+// letters with more than two cases, which naive upper/lower mapping misses.
+func (g *generator) foldRune(r rune) rune {
+	var buf [4]rune
+	orbit := append(buf[:0], r)
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if g.allow == nil || g.allow(f) {
+			orbit = append(orbit, f)
+		}
+	}
+	if g.allow != nil && !g.allow(r) && len(orbit) > 1 {
+		orbit = orbit[1:]
+	}
+	return orbit[g.rng.Intn(len(orbit))]
+}
+
+// repeat generates sub count times, drawing fresh random decisions for each
+// copy.
+func (g *generator) repeat(sub *syntax.Regexp, count int) error {
+	if g.x.distinctAlternates {
+		saved := g.dealt
+		g.dealt = make(map[*syntax.Regexp]*dealer)
+		defer func() { g.dealt = saved }()
+	}
+	if plainLiteral(sub) {
+		if count == 0 {
+			return nil
+		}
+		start := len(g.buf)
+		g.appendLiteral(sub.Rune, count)
+		g.literalRun([]*syntax.Regexp{sub}, start)
+		return nil
+	}
+	if g.steer != nil {
+		return g.steerRepeat(sub, count)
+	}
+	for i := 0; i < count; i++ {
+		if i == 1 && g.shape != nil {
+			// only the first copy counts towards the shape
+			g.shapeMuted++
+			defer func() { g.shapeMuted-- }()
+		}
+		if err := g.makeMatch(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// classRune returns a random rune from the char class re.
+func (g *generator) classRune(re *syntax.Regexp) rune {
+	switch {
+	case g.dealt != nil:
+		return g.dealRune(g.dealt, re)
+	case g.variety != nil:
+		return g.dealRune(g.variety, re)
+	case g.diverse != nil:
+		return g.dealRune(g.diverse, re)
+	}
+	return g.pickRune(re.Rune, g.x.sizes(re))
+}
+
+// pickRune returns a rune from ranges, a list of inclusive lo, hi pairs as
+// stored in a char class, preferring runes the generator allows. sizes are
+// the cumulative sizes of the ranges, or nil to scan them.
+func (g *generator) pickRune(ranges []rune, sizes []int64) rune {
+	r := g.drawRune(ranges, sizes)
+	if g.allow != nil && !g.allow(r) {
+		r = g.pickAllowed(ranges, sizes, r)
+	}
+	if g.seen != nil {
+		r = g.reuseRune(ranges, r)
+	}
+	return r
+}
+
+// drawRune returns a random rune from ranges. Each rune is equally likely
+// unless an edge bias is configured, in which case a range endpoint is
+// sometimes chosen instead. Given the cumulative sizes of the ranges, the
+// rune is found by binary search rather than by scanning them, an
+// identical result for the same draw.
+func (g *generator) drawRune(ranges []rune, sizes []int64) rune {
+	if p := g.x.edgeBias; p > 0 && g.rng.Float64() < p {
+		i := 2 * g.rng.Intn(len(ranges)/2)
+		return ranges[i+g.rng.Intn(2)]
+	}
+	if g.x.runeWeights != nil {
+		if r, ok := g.drawWeighted(ranges); ok {
+			return r
+		}
+	}
+	if sizes != nil {
+		return nthRune(ranges, sizes, g.rng.Int63n(sizes[len(sizes)-1]))
+	}
+	n := g.rng.Int63n(classSize(ranges))
+	for i := 0; i < len(ranges); i += 2 {
+		size := int64(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	panic("unreachable")
 }
 
 // generate takes in tokens in the form of:
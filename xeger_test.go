@@ -1,9 +1,17 @@
 package xeger
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
+	"math/rand"
 	"os"
+	"regexp"
+	"regexp/syntax"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 )
 
 func TestEarlyErr(t *testing.T) {
@@ -31,7 +39,599 @@ func TestEarlyErr(t *testing.T) {
 		if iRe != nil {
 			// pass in standard log settings
 			iRe.logger = log.New(os.Stderr, "", log.LstdFlags)
-			_ = iRe.Generate()
+			if _, err := iRe.Generate(); err != nil {
+				t.Errorf("expected no generation error, got %v", err)
+			}
 		}
 	}
 }
+
+func TestAnchorsOnly(t *testing.T) {
+	var tests = []string{
+		`^`,
+		`$`,
+		`^$`,
+		`\A\z`,
+		`^\z`,
+		`\A$`,
+		`(?m)^$`,
+		`^^$$`,
+	}
+
+	for _, pattern := range tests {
+		iRe, err := NewInverseRegex(pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		got, err := iRe.Generate()
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", pattern, err)
+		}
+		if got != "" {
+			t.Errorf("%s: expected empty string, got %q", pattern, got)
+		}
+	}
+}
+
+func TestCharClass(t *testing.T) {
+	var tests = []string{
+		`[a-z]`,
+		`[0-9a-f]`,
+		`[xyz]`,
+		`[\p{Greek}]`,
+		`[A-Za-z_]`,
+	}
+
+	for _, pattern := range tests {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", pattern, err)
+		}
+		for i := 0; i < 100; i++ {
+			if _, err := iRe.GenerateValid(); err != nil {
+				t.Errorf("%s: %v", pattern, err)
+				break
+			}
+		}
+	}
+}
+
+func TestExactRepeat(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`a{3}`, "aaa"},
+		{`(ab){3}`, "ababab"},
+		{`(?:xy){2}z{0}`, "xyxy"},
+		{`a{1}b{2}c{3}`, "abbccc"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got, err := iRe.Generate()
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", test.Pattern, err)
+		}
+		if got != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+}
+
+func TestExactRepeatRegeneratesSub(t *testing.T) {
+	iRe, err := NewInverseRegex(`([ab]){3}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		s, err := iRe.GenerateValid()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[s] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("expected all 8 combinations of ([ab]){3}, saw %d", len(seen))
+	}
+}
+
+func TestRepeatedClassVariesPerPosition(t *testing.T) {
+	for _, pattern := range []string{`[a-z]{5}`, `[a-z][a-z][a-z][a-z][a-z]`} {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var positions [5]map[byte]bool
+		for i := range positions {
+			positions[i] = make(map[byte]bool)
+		}
+		repeated := 0
+		for i := 0; i < 200; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for j := range positions {
+				positions[j][s[j]] = true
+			}
+			if strings.Count(s, s[:1]) == len(s) {
+				repeated++
+			}
+		}
+		for j, letters := range positions {
+			if len(letters) < 20 {
+				t.Errorf("%s: position %d only saw %d letters in 200 strings", pattern, j, len(letters))
+			}
+		}
+		if repeated > 1 {
+			t.Errorf("%s: %d of 200 strings repeated a single letter", pattern, repeated)
+		}
+	}
+}
+
+func TestGenerateWith(t *testing.T) {
+	a, err := NewInverseRegex(`[a-z]{8}`, WithSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewInverseRegex(`[a-z]{8}`, WithSeed(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s1, err := a.GenerateWith(rand.New(rand.NewSource(99)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := a.GenerateWith(rand.New(rand.NewSource(99)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1 != s2 {
+		t.Errorf("same rng seed gave %q and %q", s1, s2)
+	}
+
+	// a's own sequence must be unaffected by the GenerateWith calls
+	for i := 0; i < 5; i++ {
+		ga, _ := a.Generate()
+		gb, _ := b.Generate()
+		if ga != gb {
+			t.Fatalf("call %d: got %q, want %q", i, ga, gb)
+		}
+	}
+}
+
+func TestPOSIXClasses(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Valid   func(r rune) bool
+	}{
+		{`[[:digit:]]`, func(r rune) bool { return r >= '0' && r <= '9' }},
+		{`[[:alpha:]]`, func(r rune) bool { return r <= unicode.MaxASCII && unicode.IsLetter(r) }},
+		{`[[:upper:]]`, func(r rune) bool { return r >= 'A' && r <= 'Z' }},
+		{`[[:lower:]]`, func(r rune) bool { return r >= 'a' && r <= 'z' }},
+		{`[[:alnum:]]`, func(r rune) bool { return r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) }},
+		{`[[:xdigit:]]`, func(r rune) bool { return strings.ContainsRune("0123456789abcdefABCDEF", r) }},
+		{`[[:space:]]`, func(r rune) bool { return strings.ContainsRune("\t\n\v\f\r ", r) }},
+		{`[[:blank:]]`, func(r rune) bool { return r == ' ' || r == '\t' }},
+		{`[[:punct:]]`, func(r rune) bool { return r <= unicode.MaxASCII && (unicode.IsPunct(r) || unicode.IsSymbol(r)) }},
+		{`[[:word:]]`, func(r rune) bool { return r == '_' || r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) }},
+		{`[[:cntrl:]]`, func(r rune) bool { return r < 0x20 || r == 0x7f }},
+		{`[[:graph:]]`, func(r rune) bool { return r > ' ' && r < 0x7f }},
+		{`[[:print:]]`, func(r rune) bool { return r >= ' ' && r < 0x7f }},
+		{`[[:digit:][:upper:]]`, func(r rune) bool { return r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' }},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		for i := 0; i < 200; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if r := []rune(s); len(r) != 1 || !test.Valid(r[0]) {
+				t.Fatalf("%s: got %q", test.Pattern, s)
+			}
+		}
+	}
+}
+
+func TestFoldCaseOrbit(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    []string
+	}{
+		{`(?i)ς`, []string{"Σ", "σ", "ς"}},
+		{`(?i)k`, []string{"k", "K", "\u212a"}}, // Kelvin sign
+		{`(?i)ß`, []string{"ß", "ẞ"}},
+		{`(?i)é`, []string{"é", "É"}},
+		{`(?i)ab`, []string{"ab", "aB", "Ab", "AB"}},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, WithSeed(1), WithFoldStrategy(FoldRandom))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		seen := make(map[string]bool)
+		for i := 0; i < 200; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			seen[s] = true
+		}
+		for _, w := range test.Want {
+			if !seen[w] {
+				t.Errorf("%s: never generated %q", test.Pattern, w)
+			}
+		}
+		if len(seen) != len(test.Want) {
+			t.Errorf("%s: generated %d distinct strings, want %d", test.Pattern, len(seen), len(test.Want))
+		}
+	}
+}
+
+func TestGenerateChecked(t *testing.T) {
+	iRe, err := NewInverseRegex(`[a-z]{3}-[0-9]{2}`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, valid := iRe.GenerateChecked()
+	if !valid {
+		t.Errorf("expected %q to be reported valid", s)
+	}
+
+	// force a mismatch by checking against a different pattern
+	other, err := NewInverseRegex(`[0-9]+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iRe.regexp = other.regexp
+	if s, valid := iRe.GenerateChecked(); valid {
+		t.Errorf("expected %q to be reported invalid", s)
+	}
+}
+
+func TestQuotedLiterals(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`\Qa.b*c\E`, "a.b*c"},
+		{`\Q(x|y)+\E`, "(x|y)+"},
+		{`\Q[^a]{2}$\E`, "[^a]{2}$"},
+		{`pre\Q.*\Epost`, "pre.*post"},
+		{`\Qunterminated.?`, "unterminated.?"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		got, err := iRe.GenerateValid()
+		if err != nil {
+			t.Errorf("%s: %v", test.Pattern, err)
+		}
+		if got != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+}
+
+func TestEmptyMatchInConcat(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    string
+	}{
+		{`a(?:)b`, "ab"},
+		{`a()b`, "ab"},
+		{`x(?:|)y`, "xy"},
+		{`(?:)(?:)z(?:)`, "z"},
+		{`p(?:q{0})r`, "pr"},
+	}
+
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if !strings.Contains(iRe.DumpTree(), "OpEmptyMatch") && !strings.Contains(iRe.DumpTree(), "max=0") {
+			t.Fatalf("%s: expected an empty match in the tree:\n%s", test.Pattern, iRe.DumpTree())
+		}
+		got, err := iRe.GenerateValid()
+		if err != nil {
+			t.Errorf("%s: %v", test.Pattern, err)
+		}
+		if got != test.Want {
+			t.Errorf("%s: got %q, want %q", test.Pattern, got, test.Want)
+		}
+	}
+}
+
+func TestEmptyMatchInSimplifiedConcat(t *testing.T) {
+	iRe, err := NewInverseRegex(`xa{0}y(?:b{0})+z`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iRe.re = iRe.re.Simplify()
+	if !strings.Contains(iRe.DumpTree(), "OpEmptyMatch") {
+		t.Fatalf("expected Simplify to leave empty matches:\n%s", iRe.DumpTree())
+	}
+	got, err := iRe.GenerateValid()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "xyz" {
+		t.Errorf("got %q, want %q", got, "xyz")
+	}
+}
+
+func TestGenerateSatisfying(t *testing.T) {
+	iRe, err := NewInverseRegex(`20[0-9]{2}-[01][0-9]-[0-3][0-9]`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	isDate := func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateSatisfying(isDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isDate(s) {
+			t.Fatalf("%q is not a calendar date", s)
+		}
+	}
+
+	never := func(string) bool { return false }
+	if _, err := iRe.GenerateSatisfying(never); !errors.Is(err, ErrRetryExhausted) {
+		t.Errorf("got %v, want ErrRetryExhausted", err)
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	iRe, err := NewInverseRegex(`^[0-9a-z]+\[[0-9]{3,5}\]$`, WithSeed(1))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := iRe.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEmptyAlternationBranch(t *testing.T) {
+	for _, pattern := range []string{`(abc|)`, `(|abc)`, `x(?:abc|)y`} {
+		iRe, err := NewInverseRegex(pattern, WithSeed(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(iRe.DumpTree(), "OpAlternate") {
+			t.Fatalf("%s: expected an alternation in the tree:\n%s", pattern, iRe.DumpTree())
+		}
+		seen := make(map[string]bool)
+		for i := 0; i < 100 && len(seen) < 2; i++ {
+			s, err := iRe.Generate()
+			if err != nil {
+				t.Fatalf("%s: %v", pattern, err)
+			}
+			if !iRe.regexp.MatchString(s) {
+				t.Fatalf("%s: %q does not match", pattern, s)
+			}
+			seen[s] = true
+		}
+		if len(seen) != 2 {
+			t.Errorf("%s: got only %v, want both branches", pattern, seen)
+		}
+	}
+}
+
+func TestCanGenerate(t *testing.T) {
+	a, err := NewInverseRegex(`[a-z]{4}-[0-9]+`, WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewInverseRegex(`[a-z]{4}-[0-9]+`, WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CanGenerate(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := a.Generate()
+	want, _ := b.Generate()
+	if got != want {
+		t.Errorf("CanGenerate disturbed the sequence: got %q, want %q", got, want)
+	}
+
+	var tests = []struct {
+		Pattern string
+		Opts    []Option
+		Want    error
+	}{
+		{`[^\x00-\x{10FFFF}]`, nil, ErrNoMatch},
+		{`(?U)a+`, nil, nil},
+		{`[a-z]+`, []Option{WithMaxReps(2), WithLengthRange(5, 6)}, ErrRetryExhausted},
+	}
+	for _, test := range tests {
+		iRe, err := NewInverseRegex(test.Pattern, test.Opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.Pattern, err)
+		}
+		if err := iRe.CanGenerate(); !errors.Is(err, test.Want) {
+			t.Errorf("%s: got %v, want %v", test.Pattern, err, test.Want)
+		}
+	}
+}
+
+func TestStarOverCapturedAlternation(t *testing.T) {
+	// the parser turns (a|b) into a char class, so (ab|cd) covers an
+	// alternation proper
+	for _, pattern := range []string{`(a|b)*`, `(ab|cd)*`, `x(ab|c|)*y`} {
+		seen := make(map[string]bool)
+		mixed := false
+		for seed := int64(1); seed <= 50; seed++ {
+			iRe, err := NewInverseRegex(pattern, WithSeed(seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := iRe.Generate()
+			if err != nil {
+				t.Fatalf("%s: %v", pattern, err)
+			}
+			if !iRe.regexp.MatchString(s) {
+				t.Fatalf("%s: %q does not match", pattern, s)
+			}
+			seen[s] = true
+			if strings.ContainsAny(s, "a") && strings.ContainsAny(s, "c") || strings.Contains(s, "ab") && strings.Contains(s, "ba") {
+				mixed = true
+			}
+		}
+		if len(seen) < 5 {
+			t.Errorf("%s: only %d distinct results across 50 seeds", pattern, len(seen))
+		}
+		if !mixed {
+			t.Errorf("%s: no result mixed branches across iterations: %v", pattern, seen)
+		}
+	}
+}
+
+func TestConcatSiblingsIndependent(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Want    []string
+	}{
+		{`a(b|c)(b|c)`, []string{"abb", "abc", "acb", "acc"}},
+		{`a(bx|cy)(bx|cy)`, []string{"abxbx", "abxcy", "acybx", "acycy"}},
+	}
+
+	for _, test := range tests {
+		seen := make(map[string]bool)
+		for seed := int64(1); seed <= 100; seed++ {
+			iRe, err := NewInverseRegex(test.Pattern, WithSeed(seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			seen[s] = true
+		}
+		for _, want := range test.Want {
+			if !seen[want] {
+				t.Errorf("%s: %q never occurred across 100 seeds, got %v", test.Pattern, want, seen)
+			}
+		}
+	}
+}
+
+func TestNewFromSyntax(t *testing.T) {
+	var tests = []struct {
+		Pattern string
+		Flags   syntax.Flags
+		Simple  bool
+	}{
+		{`[a-z]{2,4}-[0-9]+`, syntax.Perl, false},
+		{`(ab|cd){2,3}x`, syntax.Perl, true},
+		{`hello`, syntax.Perl | syntax.FoldCase, false},
+		{`a.b`, syntax.POSIX, true},
+	}
+
+	for _, test := range tests {
+		re, err := syntax.Parse(test.Pattern, test.Flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.Simple {
+			re = re.Simplify()
+		}
+		want := regexp.MustCompile(`^(?:` + re.String() + `)$`)
+		iRe := NewFromSyntax(re, WithSeed(1))
+		if iRe == nil {
+			t.Fatalf("%s: got nil", test.Pattern)
+		}
+		if iRe.Pattern() != re.String() {
+			t.Errorf("%s: Pattern() = %q, want %q", test.Pattern, iRe.Pattern(), re.String())
+		}
+		for i := 0; i < 20; i++ {
+			s, err := iRe.GenerateValid()
+			if err != nil {
+				t.Fatalf("%s: %v", test.Pattern, err)
+			}
+			if !want.MatchString(s) {
+				t.Fatalf("%s: %q does not match", test.Pattern, s)
+			}
+		}
+	}
+
+	bad := &syntax.Regexp{Op: syntax.OpRepeat, Min: 5000, Max: 5000, Sub: []*syntax.Regexp{{Op: syntax.OpLiteral, Rune: []rune("a")}}}
+	if iRe := NewFromSyntax(bad); iRe != nil {
+		t.Errorf("got a Xeger for an invalid tree")
+	}
+	if iRe := NewFromSyntax(&syntax.Regexp{Op: syntax.OpEmptyMatch}, WithMaxRetries(-1)); iRe != nil {
+		t.Errorf("got a Xeger despite a failing option")
+	}
+	if _, err := FromSyntax(bad); err == nil {
+		t.Errorf("expected an error for an invalid tree")
+	}
+	if _, err := FromSyntax(&syntax.Regexp{Op: syntax.OpEmptyMatch}, WithMaxRetries(-1)); err == nil {
+		t.Errorf("expected an error for a failing option")
+	}
+
+	// surrogates are dropped from a copy, not from the caller's tree
+	re, err := syntax.Parse(`[\x{D000}-\x{E000}]`, syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := re.String()
+	if _, err := FromSyntax(re); err != nil {
+		t.Fatal(err)
+	}
+	if re.String() != before {
+		t.Errorf("tree changed from %s to %s", before, re.String())
+	}
+}
+
+func TestGenerateDecodable(t *testing.T) {
+	iRe, err := NewInverseRegex(`\{"n":[0-9]{1,2}(?:,|\})`, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		s, err := iRe.GenerateDecodable(func(s string) error {
+			var v map[string]int
+			return json.Unmarshal([]byte(s), &v)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(s, "}") {
+			t.Fatalf("%q was accepted by the decoder", s)
+		}
+	}
+
+	errOdd := errors.New("odd")
+	iRe, err = NewInverseRegex(`[13579]`, WithMaxRetries(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = iRe.GenerateDecodable(func(string) error { return errOdd })
+	if !errors.Is(err, ErrRetryExhausted) || !errors.Is(err, errOdd) {
+		t.Errorf("got %v, want ErrRetryExhausted wrapping the decode error", err)
+	}
+}